@@ -0,0 +1,154 @@
+package certifiers
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/pkg/errors"
+	wire "github.com/tendermint/go-wire"
+	"github.com/tendermint/go-wire/data"
+	lc "github.com/tendermint/light-client"
+)
+
+// ProofBundle holds a proof along with everything needed to verify it
+// offline, starting from a seed the receiver already trusts.
+//
+// Root is the validator hash of that seed, Chain holds all validator
+// changes after it, and Seed is the certified checkpoint the proof
+// validates against, along with its validators.
+type ProofBundle struct {
+	ProofType  string     `json:"type"`
+	Proof      data.Bytes `json:"proof"`
+	Seed       Seed       `json:"seed"`
+	RootHeight int        `json:"root_height"`
+	Root       data.Bytes `json:"root"`
+	Chain      Seeds      `json:"chain"`
+}
+
+// NewProofBundle bundles the proof with the seed it validates against, and
+// all validator changes we trust between root and that seed
+func NewProofBundle(trusted Provider, root, seed Seed, proofType string,
+	proof lc.Proof) (ProofBundle, error) {
+
+	b := ProofBundle{
+		ProofType:  proofType,
+		Seed:       seed,
+		RootHeight: root.Height(),
+		Root:       root.Hash(),
+	}
+	if seed.Height() < root.Height() {
+		return b, errors.Errorf("Proof at %d is older than root at %d",
+			seed.Height(), root.Height())
+	}
+
+	var err error
+	b.Proof, err = proof.Marshal()
+	if err != nil {
+		return b, err
+	}
+	b.Chain, err = seedChain(trusted, root, seed.Height())
+	return b, err
+}
+
+// seedChain returns all seeds with new validators after root, up to h,
+// in order of height
+func seedChain(trusted Provider, root Seed, h int) (Seeds, error) {
+	all := Seeds{}
+	for h > root.Height() {
+		s, err := trusted.GetByHeight(h)
+		if IsSeedNotFoundErr(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if s.Height() <= root.Height() {
+			break
+		}
+		all = append(Seeds{s}, all...)
+		h = s.Height() - 1
+	}
+
+	res := Seeds{}
+	last := root.Hash()
+	for _, s := range all {
+		if !bytes.Equal(s.Hash(), last) {
+			res = append(res, s)
+			last = s.Hash()
+		}
+	}
+	return res, nil
+}
+
+// Verify checks the bundle without talking to any node.  The root must
+// be in trusted, we follow the validator changes from there up to the
+// seed, and then validate the proof against it.
+//
+// The prover is only used to unmarshal the proof.
+func (b ProofBundle) Verify(trusted Provider, chainID string,
+	prover lc.Prover) (lc.Proof, error) {
+
+	root, err := trusted.GetByHash(b.Root)
+	if err != nil {
+		return nil, errors.Wrap(err, "Bundle root is not trusted")
+	}
+
+	// just in memory, we don't want to update our state here
+	cert := NewDynamic(chainID, root.Validators)
+	cert.LastHeight = root.Height()
+	cert.UpdateHeight = root.Height()
+	for _, s := range b.Seeds() {
+		if bytes.Equal(s.Header.Hash(), root.Header.Hash()) {
+			continue // this is our trusted root
+		}
+		if bytes.Equal(s.Hash(), cert.Cert.Hash()) {
+			err = cert.Certify(s.Checkpoint)
+		} else {
+			err = cert.Update(s.Checkpoint, s.Validators)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	proof, err := prover.Unmarshal(b.Proof)
+	if err != nil {
+		return nil, err
+	}
+	if proof.BlockHeight() != uint64(b.Seed.Height()) {
+		return nil, lc.ErrHeightMismatch(int(proof.BlockHeight()), b.Seed.Height())
+	}
+	return proof, proof.Validate(b.Seed.Checkpoint)
+}
+
+// Seeds returns all seeds in the bundle, so we can store them once
+// verified
+func (b ProofBundle) Seeds() Seeds {
+	return append(b.Chain[:len(b.Chain):len(b.Chain)], b.Seed)
+}
+
+func (b ProofBundle) Write(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	var n int
+	wire.WriteBinary(b, f, &n, &err)
+	return errors.WithStack(err)
+}
+
+func LoadProofBundle(path string) (b ProofBundle, err error) {
+	var f *os.File
+	f, err = os.Open(path)
+	if err != nil {
+		return b, errors.WithStack(err)
+	}
+	defer f.Close()
+	var n int
+	wire.ReadBinaryPtr(&b, f, bundleLimit, &n, &err)
+	return b, errors.WithStack(err)
+}
+
+// bundleLimit keeps us from reading huge files into memory
+const bundleLimit = 20 * 1000 * 1000
@@ -0,0 +1,134 @@
+package certifiers_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	wire "github.com/tendermint/go-wire"
+	"github.com/tendermint/go-wire/data"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/light-client/certifiers"
+)
+
+// appHashProof just proves the app hash of a header
+type appHashProof struct {
+	Height uint64
+	Hash   data.Bytes
+}
+
+func (p appHashProof) Data() []byte             { return p.Hash }
+func (p appHashProof) BlockHeight() uint64      { return p.Height }
+func (p appHashProof) Marshal() ([]byte, error) { return wire.BinaryBytes(p), nil }
+func (p appHashProof) Validate(c lc.Checkpoint) error {
+	if !bytes.Equal(c.Header.AppHash, p.Hash) {
+		return errors.New("Wrong app hash")
+	}
+	return nil
+}
+
+type appHashProver struct{}
+
+func (appHashProver) Get(key []byte, h uint64) (lc.Proof, error) {
+	return nil, errors.New("Not supported")
+}
+
+func (appHashProver) Unmarshal(bz []byte) (lc.Proof, error) {
+	var p appHashProof
+	err := wire.ReadBinaryBytes(bz, &p)
+	return p, err
+}
+
+func TestProofBundle(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	chainID := "test-bundle"
+	keys := certifiers.GenValKeys(4)
+	trusted := certifiers.NewMemStoreProvider()
+
+	// seeds at 10, 20, 30, 40, validators change at 30
+	seeds := certifiers.Seeds{}
+	for i := 0; i < 4; i++ {
+		vals := keys.ToValidators(10, int64(i/2))
+		check := keys.GenCheckpoint(chainID, 10*(i+1), nil, vals, []byte("x"), 0, len(keys))
+		seed := certifiers.Seed{check, vals}
+		require.Nil(trusted.StoreSeed(seed))
+		seeds = append(seeds, seed)
+	}
+
+	// prove something at 45
+	vals := keys.ToValidators(10, 1)
+	appHash := []byte("the state")
+	check := keys.GenCheckpoint(chainID, 45, nil, vals, appHash, 0, len(keys))
+	seed := certifiers.Seed{check, vals}
+	proof := appHashProof{45, appHash}
+
+	bundle, err := certifiers.NewProofBundle(trusted, seeds[0], seed, "app", proof)
+	require.Nil(err, "%+v", err)
+	require.Equal(1, len(bundle.Chain))
+	assert.Equal(30, bundle.Chain[0].Height())
+
+	// the auditor only knows the root
+	auditor := certifiers.NewMemStoreProvider()
+	require.Nil(auditor.StoreSeed(seeds[0]))
+
+	// and it survives the trip to disk
+	dir, err := ioutil.TempDir("", "bundle")
+	require.Nil(err, "%+v", err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "proof.bundle")
+	require.Nil(bundle.Write(path))
+	loaded, err := certifiers.LoadProofBundle(path)
+	require.Nil(err, "%+v", err)
+
+	pr, err := loaded.Verify(auditor, chainID, appHashProver{})
+	require.Nil(err, "%+v", err)
+	assert.EqualValues(appHash, pr.Data())
+
+	// we need to trust the root
+	_, err = loaded.Verify(certifiers.NewMemStoreProvider(), chainID, appHashProver{})
+	assert.NotNil(err)
+
+	// and all of it must be for our chain
+	_, err = loaded.Verify(auditor, "other-chain", appHashProver{})
+	assert.NotNil(err)
+
+	// a proof that doesn't match the header is useless
+	bad, err := certifiers.NewProofBundle(trusted, seeds[0], seed, "app",
+		appHashProof{45, []byte("lies")})
+	require.Nil(err, "%+v", err)
+	_, err = bad.Verify(auditor, chainID, appHashProver{})
+	assert.NotNil(err)
+
+	// as is a header not signed by the validators
+	fakes := certifiers.GenValKeys(4)
+	fakeVals := fakes.ToValidators(10, 1)
+	fake := fakes.GenCheckpoint(chainID, 45, nil, fakeVals, appHash, 0, len(fakes))
+	bad, err = certifiers.NewProofBundle(trusted, seeds[0],
+		certifiers.Seed{fake, fakeVals}, "app", proof)
+	require.Nil(err, "%+v", err)
+	_, err = bad.Verify(auditor, chainID, appHashProver{})
+	assert.NotNil(err)
+
+	// we can prove something in the root itself
+	atRoot, err := certifiers.NewProofBundle(trusted, seeds[0], seeds[0], "app",
+		appHashProof{10, []byte("x")})
+	require.Nil(err, "%+v", err)
+	pr, err = atRoot.Verify(auditor, chainID, appHashProver{})
+	require.Nil(err, "%+v", err)
+	assert.EqualValues([]byte("x"), pr.Data())
+
+	// but not in another header at that height, even with the same validators
+	rootVals := keys.ToValidators(10, 0)
+	forged := keys.GenCheckpoint(chainID, 10, nil, rootVals, []byte("forged"), 0, 1)
+	bad, err = certifiers.NewProofBundle(trusted, seeds[0],
+		certifiers.Seed{forged, rootVals}, "app", appHashProof{10, []byte("forged")})
+	require.Nil(err, "%+v", err)
+	_, err = bad.Verify(auditor, chainID, appHashProver{})
+	assert.NotNil(err)
+}
@@ -0,0 +1,79 @@
+package certifiers
+
+import (
+	"container/list"
+	"sync"
+
+	lc "github.com/tendermint/light-client"
+)
+
+// DefaultCheckpointCacheSize is enough to verify a few hundred blocks
+// without going back to the node
+const DefaultCheckpointCacheSize = 1000
+
+// CheckpointCache remembers checkpoints we already certified, by height,
+// so we don't have to download and verify the same commit twice.
+// It evicts the least recently used when full, and is safe for
+// concurrent use.
+//
+// Only add checkpoints after they are certified!
+type CheckpointCache struct {
+	mtx      sync.Mutex
+	byHeight map[int]*list.Element
+	lru      *list.List
+	maxSize  int
+	stats    CacheStats
+}
+
+// NewCheckpointCache holds at most maxSize checkpoints (0 means no limit)
+func NewCheckpointCache(maxSize int) *CheckpointCache {
+	return &CheckpointCache{
+		byHeight: map[int]*list.Element{},
+		lru:      list.New(),
+		maxSize:  maxSize,
+	}
+}
+
+// Get returns the certified checkpoint at exactly height h, if we have it
+func (c *CheckpointCache) Get(h int) (lc.Checkpoint, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.byHeight[h]
+	if !ok {
+		c.stats.Misses++
+		return lc.Checkpoint{}, false
+	}
+	c.stats.Hits++
+	c.lru.MoveToFront(elem)
+	return elem.Value.(lc.Checkpoint), true
+}
+
+// Add remembers a certified checkpoint
+func (c *CheckpointCache) Add(check lc.Checkpoint) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	h := check.Height()
+	if elem, ok := c.byHeight[h]; ok {
+		elem.Value = check
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.byHeight[h] = c.lru.PushFront(check)
+
+	for c.maxSize > 0 && c.lru.Len() > c.maxSize {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.byHeight, oldest.Value.(lc.Checkpoint).Height())
+	}
+}
+
+// Stats returns the hits and misses of all lookups so far
+func (c *CheckpointCache) Stats() CacheStats {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	return stats
+}
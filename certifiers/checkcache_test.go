@@ -0,0 +1,40 @@
+package certifiers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/light-client/certifiers"
+)
+
+func TestCheckpointCache(t *testing.T) {
+	assert := assert.New(t)
+
+	chainID := "cache-test"
+	keys := certifiers.GenValKeys(4)
+	vals := keys.ToValidators(10, 0)
+	cache := certifiers.NewCheckpointCache(2)
+
+	_, ok := cache.Get(1)
+	assert.False(ok)
+
+	for h := 1; h <= 3; h++ {
+		cache.Add(keys.GenCheckpoint(chainID, h, nil, vals, []byte("cache"), 0, len(keys)))
+		// keep the first one in use
+		_, ok = cache.Get(1)
+		assert.True(ok, "%d", h)
+	}
+
+	// 2 was evicted, as it was least recently used
+	_, ok = cache.Get(2)
+	assert.False(ok)
+	check, ok := cache.Get(3)
+	if assert.True(ok) {
+		assert.Equal(3, check.Height())
+	}
+
+	stats := cache.Stats()
+	assert.EqualValues(4, stats.Hits)
+	assert.EqualValues(2, stats.Misses)
+	assert.Equal(2, stats.Size)
+}
@@ -0,0 +1,134 @@
+package client_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/light-client/certifiers"
+	"github.com/tendermint/light-client/certifiers/client"
+	merktest "github.com/tendermint/merkleeyes/testutil"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	rpctest "github.com/tendermint/tendermint/rpc/test"
+	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tmlibs/events"
+)
+
+func TestWrappedSwitch(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := rpctest.GetConfig()
+	source := client.NewHTTP(cfg.RPC.ListenAddress)
+	c := rpcclient.NewHTTP(cfg.RPC.ListenAddress, "/websocket")
+	rpcclient.WaitForHeight(c, 3, nil)
+
+	seed, err := source.GetByHeight(1)
+	require.Nil(err, "%+v", err)
+	cert := certifiers.NewInquiring(cfg.ChainID, seed.Validators,
+		certifiers.NewMemStoreProvider(), source)
+	w := client.Wrap(c, cert)
+
+	got := make(chan events.EventData, 2)
+	evt := types.EventStringNewBlockHeader()
+	evsw := c.WSEvents.EventSwitch
+	evsw.AddListenerForEvent("test", evt, func(data events.EventData) {
+		got <- data
+	})
+
+	// a real header is passed on
+	block, err := c.Block(2)
+	require.Nil(err, "%+v", err)
+	header := block.Block.Header
+	evsw.FireEvent(evt, types.TMEventData{types.EventDataNewBlockHeader{header}})
+	select {
+	case <-got:
+	case e := <-w.DroppedEvents():
+		t.Fatalf("Valid header dropped: %+v", e.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("No event received")
+	}
+
+	// a fake one is reported instead
+	fake := *header
+	fake.AppHash = []byte("fake")
+	evsw.FireEvent(evt, types.TMEventData{types.EventDataNewBlockHeader{&fake}})
+	select {
+	case <-got:
+		t.Fatal("Fake header passed on")
+	case e := <-w.DroppedEvents():
+		assert.Equal(evt, e.Event)
+		assert.NotNil(e.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Fake header not reported")
+	}
+}
+
+func TestWrappedSwitchTx(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := rpctest.GetConfig()
+	source := client.NewHTTP(cfg.RPC.ListenAddress)
+	c := rpcclient.NewHTTP(cfg.RPC.ListenAddress, "/websocket")
+	rpcclient.WaitForHeight(c, 1, nil)
+
+	seed, err := source.GetByHeight(1)
+	require.Nil(err, "%+v", err)
+	cert := certifiers.NewInquiring(cfg.ChainID, seed.Validators,
+		certifiers.NewMemStoreProvider(), source)
+	w := client.Wrap(c, cert)
+
+	// get a tx into a block
+	_, _, btx := merktest.MakeTxKV()
+	tx := types.Tx(btx)
+	res, err := c.BroadcastTxCommit(tx)
+	require.Nil(err, "%+v", err)
+	require.True(res.Height > 0)
+
+	got := make(chan events.EventData, 3)
+	evt := types.EventStringTx(tx)
+	evsw := c.WSEvents.EventSwitch
+	evsw.AddListenerForEvent("test", evt, func(data events.EventData) {
+		got <- data
+	})
+	fire := func(height int, tx types.Tx) {
+		evsw.FireEvent(evt, types.TMEventData{types.EventDataTx{Height: height, Tx: tx}})
+	}
+
+	// the committed tx is passed on once proven
+	fire(res.Height, tx)
+	select {
+	case <-got:
+	case e := <-w.DroppedEvents():
+		t.Fatalf("Valid tx dropped: %+v", e.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("No event received")
+	}
+
+	// claiming another height is reported
+	fire(res.Height+1, tx)
+	select {
+	case <-got:
+		t.Fatal("Tx with wrong height passed on")
+	case e := <-w.DroppedEvents():
+		assert.Equal(evt, e.Event)
+		assert.NotNil(e.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wrong height not reported")
+	}
+
+	// as is a tx that was never committed, without blocking the switch
+	_, _, forged := merktest.MakeTxKV()
+	start := time.Now()
+	fire(res.Height, types.Tx(forged))
+	assert.True(time.Since(start) < time.Second)
+	select {
+	case <-got:
+		t.Fatal("Forged tx passed on")
+	case e := <-w.DroppedEvents():
+		assert.Equal(evt, e.Event)
+		assert.NotNil(e.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Forged tx not reported")
+	}
+}
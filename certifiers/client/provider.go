@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/tendermint/light-client/certifiers"
@@ -12,8 +13,12 @@ import (
 
 var _ certifiers.Provider = &Provider{}
 
+// Provider reads seeds from a tendermint node over rpc.  It is safe
+// for concurrent use, e.g. as a source and witness at the same time.
 type Provider struct {
-	node       rpcclient.SignClient
+	node rpcclient.SignClient
+
+	mtx        sync.Mutex
 	lastHeight int
 }
 
@@ -30,13 +35,13 @@ func NewHTTP(remote string) *Provider {
 // StoreSeed is a noop, as clients can only read from the chain...
 func (p *Provider) StoreSeed(_ certifiers.Seed) error { return nil }
 
-// GetHash gets the most recent validator (only one available)
+// GetByHash gets the most recent validator (only one available)
 // and sees if it matches
 //
-// TODO: improve when the rpc interface supports more functionality
+// TODO: improve when the rpc interface supports lookup by hash
 func (p *Provider) GetByHash(hash []byte) (certifiers.Seed, error) {
 	var seed certifiers.Seed
-	vals, err := p.node.Validators()
+	vals, err := p.node.Validators(nil)
 	// if we get no validators, or a different height, return an error
 	if err != nil {
 		return seed, errors.WithStack(err)
@@ -49,23 +54,31 @@ func (p *Provider) GetByHash(hash []byte) (certifiers.Seed, error) {
 	return p.buildSeed(vals)
 }
 
-// GetByHeight gets the most recent validator (only one available)
-// and sees if it matches
-//
-// TODO: keep track of most recent height, it will never go down
-//
-// TODO: improve when the rpc interface supports more functionality
+// GetByHeight gets the validator set and commit at height h, so
+// we return the exact seed at h. If h is past the current height
+// of the chain, we return the most recent seed.
 func (p *Provider) GetByHeight(h int) (certifiers.Seed, error) {
 	var seed certifiers.Seed
-	vals, err := p.node.Validators()
-	// if we get no validators, or a different height, return an error
+	if h <= 0 {
+		return seed, certifiers.ErrSeedNotFound()
+	}
+
+	// we cannot ask for anything beyond the most recent block
+	if h > p.getHeight() {
+		vals, err := p.node.Validators(nil)
+		if err != nil {
+			return seed, errors.WithStack(err)
+		}
+		p.updateHeight(vals.BlockHeight)
+		if h >= vals.BlockHeight {
+			return p.buildSeed(vals)
+		}
+	}
+
+	vals, err := p.node.Validators(&h)
 	if err != nil {
 		return seed, errors.WithStack(err)
 	}
-	p.updateHeight(vals.BlockHeight)
-	if vals.BlockHeight > h {
-		return seed, certifiers.ErrSeedNotFound()
-	}
 	return p.buildSeed(vals)
 }
 
@@ -82,7 +95,15 @@ func (p *Provider) buildSeed(vals *ctypes.ResultValidators) (certifiers.Seed, er
 	return seed, errors.WithStack(err)
 }
 
+func (p *Provider) getHeight() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.lastHeight
+}
+
 func (p *Provider) updateHeight(h int) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
 	if h > p.lastHeight {
 		p.lastHeight = h
 	}
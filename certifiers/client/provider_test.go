@@ -34,8 +34,16 @@ func TestProvider(t *testing.T) {
 	assert.Nil(seed.ValidateBasic(chainID))
 	cert := certifiers.NewStatic(chainID, seed.Validators)
 
-	// can't get a lower one
+	// we can also get the exact seed at a lower height
 	seed, err = p.GetByHeight(sh - 1)
+	require.Nil(err, "%+v", err)
+	assert.Equal(sh-1, seed.Height())
+	assert.Nil(seed.ValidateBasic(chainID))
+	err = cert.Certify(seed.Checkpoint)
+	assert.Nil(err, "%+v", err)
+
+	// but nothing before the chain started
+	_, err = p.GetByHeight(0)
 	assert.NotNil(err)
 	assert.True(certifiers.IsSeedNotFoundErr(err))
 
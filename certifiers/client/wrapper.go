@@ -1,8 +1,11 @@
 package client
 
 import (
+	"bytes"
 	"fmt"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/tendermint/go-wire/data"
 	lc "github.com/tendermint/light-client"
 	"github.com/tendermint/light-client/certifiers"
@@ -11,37 +14,70 @@ import (
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	"github.com/tendermint/tendermint/types"
 	"github.com/tendermint/tmlibs/events"
+	"github.com/tendermint/tmlibs/log"
 )
 
 var _ rpcclient.Client = Wrapper{}
 
 type Wrapper struct {
 	rpcclient.Client
-	cert *certifiers.InquiringCertifier
+	cert    *certifiers.InquiringCertifier
+	cache   *certifiers.CheckpointCache
+	report  *reporter
+	genesis *lc.TrustedGenesis
 }
 
+// Wrap verifies all results from c with cert, caching up to
+// DefaultCheckpointCacheSize certified headers
 func Wrap(c rpcclient.Client, cert *certifiers.InquiringCertifier) Wrapper {
-	wrap := Wrapper{c, cert}
+	cache := certifiers.NewCheckpointCache(certifiers.DefaultCheckpointCacheSize)
+	return WrapWithCache(c, cert, cache)
+}
+
+// WrapWithCache lets you share the cache of certified headers
+func WrapWithCache(c rpcclient.Client, cert *certifiers.InquiringCertifier,
+	cache *certifiers.CheckpointCache) Wrapper {
+
+	wrap := Wrapper{c, cert, cache, newReporter(), nil}
 	// if we wrap http client, then we can swap out the event switch to filter
 	if hc, ok := c.(*rpcclient.HTTP); ok {
 		evt := hc.WSEvents.EventSwitch
-		hc.WSEvents.EventSwitch = WrappedSwitch{evt, wrap}
+		hc.WSEvents.EventSwitch = WrappedSwitch{evt, wrap, wrap.report}
 	}
 	return wrap
 }
 
+// WithGenesis returns a Wrapper that also verifies the genesis doc
+// against what we recorded on init
+func (w Wrapper) WithGenesis(genesis lc.TrustedGenesis) Wrapper {
+	w.genesis = &genesis
+	return w
+}
+
+// SetLogger reports all dropped events to the logger
+func (w Wrapper) SetLogger(logger log.Logger) {
+	w.report.logger = logger
+}
+
+// DroppedEvents returns a channel with all events that failed
+// verification, and were not passed on to subscribers.
+//
+// If nobody reads them, we drop the reports once the buffer is full
+// (they are still logged).
+func (w Wrapper) DroppedEvents() <-chan EventError {
+	return w.report.dropped
+}
+
 func (w Wrapper) ABCIQuery(path string, data data.Bytes, prove bool) (*ctypes.ResultABCIQuery, error) {
 	r, err := w.Client.ABCIQuery(path, data, prove)
 	if !prove || err != nil {
 		return r, err
 	}
 	// get a verified commit to validate from
-	c, err := w.Commit(int(r.Height))
+	check, err := w.certified(int(r.Height))
 	if err != nil {
 		return nil, err
 	}
-	// make sure the checkpoint and proof match up
-	check := lc.CheckpointFromResult(c)
 	// verify query
 	proof := proofs.AppProof{
 		Height: r.Height,
@@ -59,21 +95,42 @@ func (w Wrapper) Tx(hash []byte, prove bool) (*ctypes.ResultTx, error) {
 		return r, err
 	}
 	// get a verified commit to validate from
-	c, err := w.Commit(r.Height)
+	check, err := w.certified(r.Height)
 	if err != nil {
 		return nil, err
 	}
-	// make sure the checkpoint and proof match up
-	check := lc.CheckpointFromResult(c)
 	// verify tx
-	proof := proofs.TxProof{
-		Height: uint64(r.Height),
-		Proof:  r.Proof,
-	}
+	proof := proofs.NewTxProof(uint64(r.Height), r.Proof)
 	err = proof.Validate(check)
 	return r, err
 }
 
+// BroadcastTxCommit only returns a result if we can prove the tx is in
+// the block at the reported height, otherwise an ErrTxNotCommitted.
+//
+// If the tx was rejected by CheckTx it never made it into a block, so
+// there is nothing to prove and we just return the result.
+func (w Wrapper) BroadcastTxCommit(tx types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	r, err := w.Client.BroadcastTxCommit(tx)
+	if err != nil || r.CheckTx.IsErr() {
+		return r, err
+	}
+
+	hash := tx.Hash()
+	if !bytes.Equal(r.Hash, hash) {
+		err = errors.Errorf("Node returned hash %X", r.Hash)
+		return nil, lc.ErrTxNotCommitted(hash, r.Height, err)
+	}
+	res, err := getTx(w, hash)
+	if err == nil {
+		err = checkTx(res, r.Height, tx)
+	}
+	if err != nil {
+		return nil, lc.ErrTxNotCommitted(hash, r.Height, err)
+	}
+	return r, nil
+}
+
 func (w Wrapper) BlockchainInfo(minHeight, maxHeight int) (*ctypes.ResultBlockchainInfo, error) {
 	r, err := w.Client.BlockchainInfo(minHeight, maxHeight)
 	if err != nil {
@@ -81,17 +138,26 @@ func (w Wrapper) BlockchainInfo(minHeight, maxHeight int) (*ctypes.ResultBlockch
 	}
 
 	// go and verify every blockmeta in the result....
+	// if a header is linked to the next one we verified, we can trust it
+	// without downloading another commit
+	var next *types.Header
 	for _, meta := range r.BlockMetas {
+		if next != nil && next.Height == meta.Header.Height+1 &&
+			bytes.Equal(next.LastBlockID.Hash, meta.Header.Hash()) {
+			next = meta.Header
+			continue
+		}
+
 		// get a checkpoint to verify from
-		c, err := w.Commit(meta.Header.Height)
+		check, err := w.certified(meta.Header.Height)
 		if err != nil {
 			return nil, err
 		}
-		check := lc.CheckpointFromResult(c)
 		err = proofs.ValidateBlockMeta(meta, check)
 		if err != nil {
 			return nil, err
 		}
+		next = meta.Header
 	}
 
 	return r, nil
@@ -103,11 +169,10 @@ func (w Wrapper) Block(height int) (*ctypes.ResultBlock, error) {
 		return nil, err
 	}
 	// get a checkpoint to verify from
-	c, err := w.Commit(height)
+	check, err := w.certified(height)
 	if err != nil {
 		return nil, err
 	}
-	check := lc.CheckpointFromResult(c)
 
 	// now verify
 	err = proofs.ValidateBlockMeta(r.BlockMeta, check)
@@ -121,52 +186,235 @@ func (w Wrapper) Block(height int) (*ctypes.ResultBlock, error) {
 	return r, nil
 }
 
+// Genesis makes sure the genesis is for our chain, and matches the one
+// we trust if set with WithGenesis
+func (w Wrapper) Genesis() (*ctypes.ResultGenesis, error) {
+	r, err := w.Client.Genesis()
+	if err != nil {
+		return nil, err
+	}
+	trusted := lc.TrustedGenesis{ChainID: w.cert.ChainID()}
+	if w.genesis != nil {
+		trusted = *w.genesis
+	}
+	err = trusted.Verify(r.Genesis)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validators makes sure the validators match the ValidatorsHash of a
+// certified header at that height
+func (w Wrapper) Validators(height *int) (*ctypes.ResultValidators, error) {
+	r, err := w.Client.Validators(height)
+	if err != nil {
+		return nil, err
+	}
+	if height != nil && r.BlockHeight != *height {
+		return nil, lc.ErrHeightMismatch(*height, r.BlockHeight)
+	}
+	check, err := w.certified(r.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := types.NewValidatorSet(r.Validators)
+	if !bytes.Equal(vals.Hash(), check.Header.ValidatorsHash) {
+		return nil, errors.Errorf("Validators %X don't match header %X",
+			vals.Hash(), check.Header.ValidatorsHash)
+	}
+	return r, nil
+}
+
+// statusWait is how long Status waits for the next block, to prove the
+// latest app hash
+const statusWait = 2 * time.Second
+
+// Status makes sure the latest block hash matches a certified header.
+//
+// The latest app hash is only included in the next header, so we wait
+// up to statusWait for it.  If the chain doesn't move on in time, we
+// cannot prove the app hash, and we clear LatestAppHash rather than
+// pass it on unverified.
+func (w Wrapper) Status() (*ctypes.ResultStatus, error) {
+	r, err := w.Client.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := w.certified(r.LatestBlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(r.LatestBlockHash, check.Header.Hash()) {
+		return nil, errors.Errorf("Latest block %X doesn't match header %X",
+			r.LatestBlockHash, check.Header.Hash())
+	}
+
+	h := r.LatestBlockHeight + 1
+	err = rpcclient.WaitForHeight(w.Client, h, waitUntil(time.Now().Add(statusWait)))
+	if err != nil {
+		r.LatestAppHash = nil
+		return r, nil
+	}
+	next, err := w.certified(h)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(r.LatestAppHash, next.Header.AppHash) {
+		return nil, errors.Errorf("Latest app hash %X doesn't match header %X",
+			r.LatestAppHash, next.Header.AppHash)
+	}
+	return r, nil
+}
+
+// waitUntil polls for new blocks until the deadline, then gives up
+func waitUntil(deadline time.Time) rpcclient.Waiter {
+	return func(delta int) error {
+		if delta <= 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("Still waiting for %d blocks", delta)
+		}
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}
+}
+
 // Commit downloads the Commit and certifies it with the certifiers.
 //
 // This is the foundation for all other verification in this module
 func (w Wrapper) Commit(height int) (*ctypes.ResultCommit, error) {
+	// canonical commits never change, so we can serve them from the cache
+	if check, ok := w.cache.Get(height); ok {
+		return &ctypes.ResultCommit{
+			Header:          check.Header,
+			Commit:          check.Commit,
+			CanonicalCommit: true,
+		}, nil
+	}
+
 	rpcclient.WaitForHeight(w.Client, height, nil)
 	r, err := w.Client.Commit(height)
 	// if we got it, then certify it
 	if err == nil {
 		check := lc.CheckpointFromResult(r)
 		err = w.cert.Certify(check)
+		if err == nil && r.CanonicalCommit {
+			w.cache.Add(check)
+		}
 	}
 	return r, err
 }
 
+// certified returns a certified checkpoint at this height, from the
+// cache if possible
+func (w Wrapper) certified(height int) (lc.Checkpoint, error) {
+	c, err := w.Commit(height)
+	if err != nil {
+		return lc.Checkpoint{}, err
+	}
+	check := lc.CheckpointFromResult(c)
+	if check.Height() != height {
+		return check, lc.ErrHeightMismatch(height, check.Height())
+	}
+	return check, nil
+}
+
+// CacheStats reports how many commits we served from the cache
+func (w Wrapper) CacheStats() certifiers.CacheStats {
+	return w.cache.Stats()
+}
+
+// droppedBuffer is how many dropped events we keep for DroppedEvents
+const droppedBuffer = 100
+
+// EventError reports an event that failed verification
+type EventError struct {
+	Event string
+	Data  events.EventData
+	Err   error
+}
+
+func (e EventError) Error() string {
+	return fmt.Sprintf("Dropped event %s: %v", e.Event, e.Err)
+}
+
+// reporter is shared by the Wrapper and WrappedSwitch, to report
+// events we drop
+type reporter struct {
+	logger  log.Logger
+	dropped chan EventError
+}
+
+func newReporter() *reporter {
+	return &reporter{
+		logger:  log.NewNopLogger(),
+		dropped: make(chan EventError, droppedBuffer),
+	}
+}
+
+func (r *reporter) drop(event string, data events.EventData, err error) {
+	e := EventError{event, data, err}
+	r.logger.Error("Dropped invalid event", "event", event, "err", err)
+	select {
+	case r.dropped <- e:
+	default:
+		r.logger.Debug("Dropped event report, nobody is listening", "event", event)
+	}
+}
+
+// WrappedSwitch only fires events we could verify.  Tx events are
+// verified in the background, so they may arrive after later events.
 type WrappedSwitch struct {
 	types.EventSwitch
 	client rpcclient.Client
+	report *reporter
 }
 
 func (s WrappedSwitch) FireEvent(event string, data events.EventData) {
 	tm, ok := data.(types.TMEventData)
 	if !ok {
-		fmt.Printf("bad type %#v\n", data)
+		s.report.drop(event, data, errors.Errorf("Unknown event type %T", data))
 		return
 	}
 
 	// check to validate it if possible, and drop if not valid
+	// other events (like votes) cannot be verified, so they pass as is
+	var err error
 	switch t := tm.Unwrap().(type) {
 	case types.EventDataNewBlockHeader:
-		err := verifyHeader(s.client, t.Header)
-		if err != nil {
-			fmt.Printf("Invalid header: %#v\n", err)
-			return
-		}
+		err = verifyHeader(s.client, t.Header)
 	case types.EventDataNewBlock:
-		err := verifyBlock(s.client, t.Block)
-		if err != nil {
-			fmt.Printf("Invalid block: %#v\n", err)
-			return
-		}
+		err = verifyBlock(s.client, t.Block)
+	case types.EventDataTx:
+		// the node may not have indexed the tx yet, so we don't block
+		// the other events while we wait for its proof
+		go s.fireTx(event, data, t)
+		return
+	}
+	if err != nil {
+		s.report.drop(event, data, err)
+		return
 	}
 
 	// looks good, we fire it
 	s.EventSwitch.FireEvent(event, data)
 }
 
+// fireTx passes on the tx event once we verified its proof, or
+// reports it as dropped
+func (s WrappedSwitch) fireTx(event string, data events.EventData, tx types.EventDataTx) {
+	err := verifyTx(s.client, tx)
+	if err != nil {
+		s.report.drop(event, data, err)
+		return
+	}
+	s.EventSwitch.FireEvent(event, data)
+}
+
 func verifyHeader(c rpcclient.Client, head *types.Header) error {
 	// get a checkpoint to verify from
 	commit, err := c.Commit(head.Height)
@@ -186,3 +434,42 @@ func verifyBlock(c rpcclient.Client, block *types.Block) error {
 	check := lc.CheckpointFromResult(commit)
 	return proofs.ValidateBlock(block, check)
 }
+
+// txRetries is how often we try to get the proof for a tx event, as the
+// node may not have indexed it yet when the event fires
+const txRetries = 5
+
+// verifyTx makes sure the tx is really included in the block at the given
+// height, by getting and verifying its proof (c must be a Wrapper)
+func verifyTx(c rpcclient.Client, evt types.EventDataTx) error {
+	res, err := getTx(c, evt.Tx.Hash())
+	if err != nil {
+		return err
+	}
+	return checkTx(res, evt.Height, evt.Tx)
+}
+
+// getTx gets the tx with its proof, retrying while the node indexes it
+func getTx(c rpcclient.Client, hash []byte) (*ctypes.ResultTx, error) {
+	var res *ctypes.ResultTx
+	var err error
+	for i := 0; i < txRetries; i++ {
+		res, err = c.Tx(hash, true)
+		if err == nil {
+			return res, nil
+		}
+		time.Sleep(time.Duration(i+1) * 100 * time.Millisecond)
+	}
+	return nil, err
+}
+
+// checkTx makes sure the proven tx is the one we expect at this height
+func checkTx(res *ctypes.ResultTx, height int, tx types.Tx) error {
+	if res.Height != height {
+		return lc.ErrHeightMismatch(height, res.Height)
+	}
+	if !bytes.Equal(res.Proof.Data, tx) {
+		return errors.New("Tx doesn't match proof")
+	}
+	return nil
+}
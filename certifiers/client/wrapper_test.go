@@ -0,0 +1,275 @@
+package client_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	crypto "github.com/tendermint/go-crypto"
+	keys "github.com/tendermint/go-crypto/keys"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/light-client/certifiers"
+	"github.com/tendermint/light-client/certifiers/client"
+	merktest "github.com/tendermint/merkleeyes/testutil"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctest "github.com/tendermint/tendermint/rpc/test"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestWrapperConcurrent(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := rpctest.GetConfig()
+	source := client.NewHTTP(cfg.RPC.ListenAddress)
+	c := rpcclient.NewLocal(node)
+	rpcclient.WaitForHeight(c, 5, nil)
+
+	// trust the validators we see now
+	seed, err := source.GetByHeight(1)
+	require.Nil(err, "%+v", err)
+	cert := certifiers.NewInquiring(cfg.ChainID, seed.Validators,
+		certifiers.NewMemStoreProvider(), source)
+	w := client.Wrap(c, cert)
+
+	// all share one certifier
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(h int) {
+			defer wg.Done()
+			_, err := w.Commit(h)
+			errs <- err
+			_, err = w.Block(h)
+			errs <- err
+		}(1 + i%5)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.Nil(err, "%+v", err)
+	}
+	assert.True(cert.Cert.State().Height >= 5)
+}
+
+// run with -race: the source is also a witness, so the same Provider is
+// used from every certifying goroutine
+func TestWrapperWitnessesConcurrent(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := rpctest.GetConfig()
+	source := client.NewHTTP(cfg.RPC.ListenAddress)
+	c := rpcclient.NewLocal(node)
+	rpcclient.WaitForHeight(c, 5, nil)
+	status, err := c.Status()
+	require.Nil(err, "%+v", err)
+	top := status.LatestBlockHeight
+
+	seed, err := source.GetByHeight(1)
+	require.Nil(err, "%+v", err)
+	cert := certifiers.NewInquiring(cfg.ChainID, seed.Validators,
+		certifiers.NewMemStoreProvider(), source)
+	cert.Witnesses = []certifiers.Provider{source, client.New(c)}
+	w := client.Wrap(c, cert)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(h int) {
+			defer wg.Done()
+			_, err := w.Commit(h)
+			errs <- err
+		}(top - i%5)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.Nil(err, "%+v", err)
+	}
+	assert.Nil(cert.Fork())
+}
+
+func TestWrapperCache(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := rpctest.GetConfig()
+	source := client.NewHTTP(cfg.RPC.ListenAddress)
+	c := rpcclient.NewLocal(node)
+	rpcclient.WaitForHeight(c, 8, nil)
+
+	seed, err := source.GetByHeight(1)
+	require.Nil(err, "%+v", err)
+	cert := certifiers.NewInquiring(cfg.ChainID, seed.Validators,
+		certifiers.NewMemStoreProvider(), source)
+	w := client.Wrap(c, cert)
+
+	// the second time, we don't ask the node
+	_, err = w.Block(3)
+	require.Nil(err, "%+v", err)
+	_, err = w.Block(3)
+	require.Nil(err, "%+v", err)
+	stats := w.CacheStats()
+	assert.EqualValues(1, stats.Hits)
+	assert.EqualValues(1, stats.Misses)
+
+	// a range only needs one commit, as the headers are linked
+	info, err := w.BlockchainInfo(2, 6)
+	require.Nil(err, "%+v", err)
+	assert.Equal(5, len(info.BlockMetas))
+	stats = w.CacheStats()
+	assert.EqualValues(2, stats.Misses)
+}
+
+func TestWrapperValidatorsStatus(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := rpctest.GetConfig()
+	source := client.NewHTTP(cfg.RPC.ListenAddress)
+	c := rpcclient.NewLocal(node)
+	rpcclient.WaitForHeight(c, 3, nil)
+
+	seed, err := source.GetByHeight(1)
+	require.Nil(err, "%+v", err)
+	cert := certifiers.NewInquiring(cfg.ChainID, seed.Validators,
+		certifiers.NewMemStoreProvider(), source)
+	w := client.Wrap(c, cert)
+
+	h := 2
+	vals, err := w.Validators(&h)
+	require.Nil(err, "%+v", err)
+	assert.Equal(2, vals.BlockHeight)
+	assert.Equal(seed.Validators.Size(), len(vals.Validators))
+
+	vals, err = w.Validators(nil)
+	require.Nil(err, "%+v", err)
+	assert.True(vals.BlockHeight >= 3)
+
+	status, err := w.Status()
+	require.Nil(err, "%+v", err)
+	assert.True(status.LatestBlockHeight >= 3)
+}
+
+func TestWrapperGenesis(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := rpctest.GetConfig()
+	source := client.NewHTTP(cfg.RPC.ListenAddress)
+	c := rpcclient.NewLocal(node)
+
+	seed, err := source.GetByHeight(1)
+	require.Nil(err, "%+v", err)
+	cert := certifiers.NewInquiring(cfg.ChainID, seed.Validators,
+		certifiers.NewMemStoreProvider(), source)
+
+	// without a trusted genesis, we only check the chain
+	gen, err := client.Wrap(c, cert).Genesis()
+	require.Nil(err, "%+v", err)
+	trusted, err := lc.NewTrustedGenesis(gen.Genesis)
+	require.Nil(err, "%+v", err)
+
+	w := client.Wrap(c, cert).WithGenesis(trusted)
+	_, err = w.Genesis()
+	assert.Nil(err, "%+v", err)
+
+	// anything else is refused
+	trusted.Hash = []byte("not the right hash")
+	_, err = client.Wrap(c, cert).WithGenesis(trusted).Genesis()
+	assert.True(lc.IsGenesisMismatchErr(err), "%+v", err)
+}
+
+func TestWrapperBroadcastTxCommit(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := rpctest.GetConfig()
+	source := client.NewHTTP(cfg.RPC.ListenAddress)
+	c := rpcclient.NewLocal(node)
+	rpcclient.WaitForHeight(c, 1, nil)
+
+	seed, err := source.GetByHeight(1)
+	require.Nil(err, "%+v", err)
+	cert := certifiers.NewInquiring(cfg.ChainID, seed.Validators,
+		certifiers.NewMemStoreProvider(), source)
+	w := client.Wrap(c, cert)
+
+	// we only get a result once the tx is proven
+	_, _, btx := merktest.MakeTxKV()
+	res, err := w.BroadcastTxCommit(types.Tx(btx))
+	require.Nil(err, "%+v", err)
+	assert.EqualValues(0, res.DeliverTx.Code)
+	assert.True(res.Height > 0)
+}
+
+// testTx is a Signable that posts raw bytes, and remembers who signed it
+type testTx struct {
+	data   []byte
+	signer crypto.PubKey
+}
+
+func (t *testTx) SignBytes() []byte { return t.data }
+
+func (t *testTx) Sign(pubkey crypto.PubKey, sig crypto.Signature) error {
+	t.signer = pubkey
+	return nil
+}
+
+func (t *testTx) Signers() ([]crypto.PubKey, error) {
+	return []crypto.PubKey{t.signer}, nil
+}
+
+func (t *testTx) TxBytes() ([]byte, error) { return t.data, nil }
+
+// testSigner signs everything with one key
+type testSigner struct {
+	key crypto.PrivKey
+}
+
+func (s testSigner) Sign(name, passphrase string, tx keys.Signable) error {
+	return tx.Sign(s.key.PubKey(), s.key.Sign(tx.SignBytes()))
+}
+
+// lyingClient reports every tx one block after the one it is in
+type lyingClient struct {
+	rpcclient.Client
+}
+
+func (c lyingClient) BroadcastTxCommit(tx types.Tx) (*ctypes.ResultBroadcastTxCommit, error) {
+	res, err := c.Client.BroadcastTxCommit(tx)
+	if err == nil {
+		res.Height++
+	}
+	return res, err
+}
+
+func TestPosterPost(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cfg := rpctest.GetConfig()
+	source := client.NewHTTP(cfg.RPC.ListenAddress)
+	c := rpcclient.NewLocal(node)
+	rpcclient.WaitForHeight(c, 1, nil)
+
+	seed, err := source.GetByHeight(1)
+	require.Nil(err, "%+v", err)
+	cert := certifiers.NewInquiring(cfg.ChainID, seed.Validators,
+		certifiers.NewMemStoreProvider(), source)
+	signer := testSigner{crypto.GenPrivKeyEd25519().Wrap()}
+
+	// we sign and get a proven result
+	_, _, btx := merktest.MakeTxKV()
+	tx := &testTx{data: btx}
+	res, err := lc.NewPoster(client.Wrap(c, cert), signer).Post(tx, "me", "secret")
+	require.Nil(err, "%+v", err)
+	assert.EqualValues(0, res.DeliverTx.Code)
+	assert.True(tx.signer.Equals(signer.key.PubKey()))
+
+	// and never a result we cannot prove
+	_, _, btx = merktest.MakeTxKV()
+	poster := lc.NewPoster(client.Wrap(lyingClient{c}, cert), signer)
+	_, err = poster.Post(&testTx{data: btx}, "me", "secret")
+	assert.True(lc.IsTxNotCommittedErr(err), "%+v", err)
+}
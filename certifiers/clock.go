@@ -0,0 +1,18 @@
+package certifiers
+
+import "time"
+
+// Clock tells us the current time, so we can check the age of seeds.
+// Replace it with a MockClock to fake time in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock returns the actual time of the local machine
+var SystemClock Clock = systemClock{}
@@ -0,0 +1,320 @@
+/*
+Package db stores seeds in a tmlibs db.DB, like goleveldb.
+
+This scales much better than the files Provider with many seeds, as
+every lookup is a seek on a sorted index of heights, and each validator
+set is only stored once, no matter how many checkpoints refer to it.
+*/
+package db
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+
+	"github.com/google/btree"
+	"github.com/pkg/errors"
+	wire "github.com/tendermint/go-wire"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/light-client/certifiers"
+	"github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tmlibs/db"
+)
+
+var (
+	// checkpoints are indexed by big-endian height, so they sort
+	checkPrefix = []byte("c:")
+	// validator sets are indexed by their hash
+	valPrefix = []byte("v:")
+	// an empty record for every checkpoint, by validator hash and height
+	hashPrefix = []byte("h:")
+)
+
+var (
+	_ certifiers.Provider = (*Provider)(nil)
+	_ certifiers.Pruner   = (*Provider)(nil)
+)
+
+// Provider stores all data in a db.DB
+//
+// We store three kinds of records:
+//
+// 1. Checkpoints by height
+// 2. Validator sets by their hash (once per set)
+// 3. One empty record per checkpoint, keyed by validator hash and height
+//
+// The db iterator cannot seek, so we read the keys once when we open
+// the database, and keep the sorted heights in memory.  That is all we
+// need to find the closest checkpoint, without scanning on lookups.
+//
+// Note that we do not worry about caching, as that can be achieved by
+// pairing this with a MemStoreProvider and CacheProvider from certifiers
+type Provider struct {
+	chainID string
+	db      dbm.DB
+
+	// mtx guards the height indexes
+	mtx     sync.RWMutex
+	heights *btree.BTree
+	byHash  map[string]*btree.BTree
+}
+
+// heightItem is a stored checkpoint in the height indexes
+type heightItem struct {
+	height int
+	hash   string
+}
+
+func (i heightItem) Less(than btree.Item) bool {
+	return i.height < than.(heightItem).height
+}
+
+// NewProvider stores all seeds for chainID in the given database
+func NewProvider(chainID string, db dbm.DB) *Provider {
+	p := &Provider{
+		chainID: chainID,
+		db:      db,
+		heights: btree.New(16),
+		byHash:  map[string]*btree.BTree{},
+	}
+	p.loadHeights()
+	return p
+}
+
+// NewLevelDBProvider opens (or creates) a goleveldb database
+// called name in dir
+func NewLevelDBProvider(chainID, name, dir string) (*Provider, error) {
+	db, err := dbm.NewGoLevelDB(name, dir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return NewProvider(chainID, db), nil
+}
+
+// NewMemDBProvider is handy for tests
+func NewMemDBProvider(chainID string) *Provider {
+	return NewProvider(chainID, dbm.NewMemDB())
+}
+
+// Close releases the underlying database
+func (p *Provider) Close() {
+	p.db.Close()
+}
+
+func encodeHeight(h int) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(h))
+	return bz
+}
+
+func decodeHeight(bz []byte) int {
+	return int(binary.BigEndian.Uint64(bz))
+}
+
+func checkKey(h int) []byte {
+	return append(append([]byte{}, checkPrefix...), encodeHeight(h)...)
+}
+
+func valKey(hash []byte) []byte {
+	return append(append([]byte{}, valPrefix...), hash...)
+}
+
+func hashKey(hash []byte, h int) []byte {
+	key := append(append([]byte{}, hashPrefix...), hash...)
+	return append(key, encodeHeight(h)...)
+}
+
+// loadHeights reads the keys of all checkpoints into the indexes
+func (p *Provider) loadHeights() {
+	it := p.db.Iterator()
+	defer it.Release()
+	for it.Next() {
+		key := it.Key()
+		if !bytes.HasPrefix(key, hashPrefix) || len(key) < len(hashPrefix)+8 {
+			continue
+		}
+		split := len(key) - 8
+		p.index(heightItem{
+			height: decodeHeight(key[split:]),
+			hash:   string(key[len(hashPrefix):split]),
+		})
+	}
+}
+
+// index adds a checkpoint to the height indexes, must hold the lock
+func (p *Provider) index(item heightItem) {
+	p.heights.ReplaceOrInsert(item)
+	hs := p.byHash[item.hash]
+	if hs == nil {
+		hs = btree.New(16)
+		p.byHash[item.hash] = hs
+	}
+	hs.ReplaceOrInsert(item)
+}
+
+// unindex drops a checkpoint from the height indexes, must hold the lock
+func (p *Provider) unindex(item heightItem) {
+	p.heights.Delete(item)
+	hs := p.byHash[item.hash]
+	if hs == nil {
+		return
+	}
+	hs.Delete(item)
+	if hs.Len() == 0 {
+		delete(p.byHash, item.hash)
+	}
+}
+
+// StoreSeed writes the seed and all indexes in one batch
+func (p *Provider) StoreSeed(seed certifiers.Seed) error {
+	return p.StoreSeeds(seed)
+}
+
+// StoreSeeds writes many seeds in one batch, which is much faster
+// than storing them one by one
+func (p *Provider) StoreSeeds(seeds ...certifiers.Seed) error {
+	// make sure the seeds are self-consistent before saving any
+	for _, seed := range seeds {
+		err := seed.ValidateBasic(p.chainID)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	batch := p.db.NewBatch()
+	for _, seed := range seeds {
+		h, hash := seed.Height(), seed.Hash()
+		item := heightItem{height: h, hash: string(hash)}
+
+		// a different seed at the same height is replaced
+		if old := p.heights.Get(item); old != nil {
+			prev := old.(heightItem)
+			if prev.hash != item.hash {
+				p.unindex(prev)
+				p.deleteItem(batch, prev)
+			}
+		}
+
+		// validator sets never change for a hash, so only write them once
+		if p.byHash[item.hash] == nil {
+			batch.Set(valKey(hash), wire.BinaryBytes(seed.Validators))
+		}
+		batch.Set(checkKey(h), wire.BinaryBytes(seed.Checkpoint))
+		batch.Set(hashKey(hash, h), []byte{})
+		p.index(item)
+	}
+	batch.Write()
+	return nil
+}
+
+// GetByHeight returns the closest seed with height <= h
+func (p *Provider) GetByHeight(h int) (certifiers.Seed, error) {
+	p.mtx.RLock()
+	var found btree.Item
+	p.heights.DescendLessOrEqual(heightItem{height: h}, func(i btree.Item) bool {
+		found = i
+		return false
+	})
+	p.mtx.RUnlock()
+
+	if found == nil {
+		return certifiers.Seed{}, certifiers.ErrSeedNotFound()
+	}
+	return p.loadHeight(found.(heightItem).height)
+}
+
+// GetByHash returns the last seed stored with this validator hash
+func (p *Provider) GetByHash(hash []byte) (certifiers.Seed, error) {
+	p.mtx.RLock()
+	var found btree.Item
+	if hs := p.byHash[string(hash)]; hs != nil {
+		found = hs.Max()
+	}
+	p.mtx.RUnlock()
+
+	if found == nil {
+		return certifiers.Seed{}, certifiers.ErrSeedNotFound()
+	}
+	return p.loadHeight(found.(heightItem).height)
+}
+
+func (p *Provider) loadHeight(h int) (certifiers.Seed, error) {
+	bz := p.db.Get(checkKey(h))
+	if bz == nil {
+		return certifiers.Seed{}, certifiers.ErrSeedNotFound()
+	}
+	return p.loadSeed(bz)
+}
+
+// loadSeed reads the checkpoint and adds the validators it refers to
+func (p *Provider) loadSeed(bz []byte) (seed certifiers.Seed, err error) {
+	var check lc.Checkpoint
+	err = wire.ReadBinaryBytes(bz, &check)
+	if err != nil {
+		return seed, errors.WithStack(err)
+	}
+
+	vbz := p.db.Get(valKey(check.Header.ValidatorsHash))
+	if vbz == nil {
+		return seed, certifiers.ErrSeedNotFound()
+	}
+	vals := new(types.ValidatorSet)
+	err = wire.ReadBinaryBytes(vbz, vals)
+	if err != nil {
+		return seed, errors.WithStack(err)
+	}
+	return certifiers.Seed{Checkpoint: check, Validators: vals}, nil
+}
+
+// AllSeeds loads every checkpoint we have, sorted by height
+func (p *Provider) AllSeeds() (certifiers.Seeds, error) {
+	p.mtx.RLock()
+	all := make([]int, 0, p.heights.Len())
+	p.heights.Ascend(func(i btree.Item) bool {
+		all = append(all, i.(heightItem).height)
+		return true
+	})
+	p.mtx.RUnlock()
+
+	res := make(certifiers.Seeds, 0, len(all))
+	for _, h := range all {
+		seed, err := p.loadHeight(h)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, seed)
+	}
+	return res, nil
+}
+
+// DeleteSeed removes the checkpoint at this height.  If it was the
+// last one for its validator hash, we also delete the validator set.
+func (p *Provider) DeleteSeed(seed certifiers.Seed) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	found := p.heights.Get(heightItem{height: seed.Height()})
+	if found == nil {
+		return nil
+	}
+	item := found.(heightItem)
+	p.unindex(item)
+	batch := p.db.NewBatch()
+	p.deleteItem(batch, item)
+	batch.Write()
+	return nil
+}
+
+// deleteItem removes the records of an unindexed checkpoint, and its
+// validators if no other checkpoint refers to them
+func (p *Provider) deleteItem(batch dbm.Batch, item heightItem) {
+	hash := []byte(item.hash)
+	batch.Delete(checkKey(item.height))
+	batch.Delete(hashKey(hash, item.height))
+	if p.byHash[item.hash] == nil {
+		batch.Delete(valKey(hash))
+	}
+}
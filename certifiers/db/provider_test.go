@@ -0,0 +1,170 @@
+package db_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/light-client/certifiers"
+	"github.com/tendermint/light-client/certifiers/db"
+)
+
+func TestMemDBProvider(t *testing.T) {
+	chainID := "test-memdb"
+	p := db.NewMemDBProvider(chainID)
+	defer p.Close()
+	checkProvider(t, p, chainID)
+}
+
+func TestLevelDBProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dbprovider-test")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	chainID := "test-leveldb"
+	p, err := db.NewLevelDBProvider(chainID, "seeds", dir)
+	require.Nil(t, err, "%+v", err)
+	checkProvider(t, p, chainID)
+	p.Close()
+
+	// make sure it is all still there after re-opening
+	p, err = db.NewLevelDBProvider(chainID, "seeds", dir)
+	require.Nil(t, err, "%+v", err)
+	defer p.Close()
+	seed, err := p.GetByHeight(5000)
+	require.Nil(t, err, "%+v", err)
+	assert.Equal(t, 110, seed.Height())
+	seed, err = p.GetByHeight(47)
+	require.Nil(t, err, "%+v", err)
+	assert.Equal(t, 40, seed.Height())
+	all, err := p.AllSeeds()
+	require.Nil(t, err, "%+v", err)
+	assert.Equal(t, 10, len(all))
+}
+
+func checkProvider(t *testing.T, p *db.Provider, chainID string) {
+	assert, require := assert.New(t), require.New(t)
+	appHash := []byte("some-data")
+	keys := certifiers.GenValKeys(5)
+	count := 10
+
+	// make a bunch of seeds...
+	seeds := make([]certifiers.Seed, count)
+	for i := 0; i < count; i++ {
+		// two seeds for each validator, to check how we handle dups
+		// (10, 0), (10, 1), (10, 1), (10, 2), (10, 2), ...
+		vals := keys.ToValidators(10, int64(i/2))
+		h := 20 + 10*i
+		check := keys.GenCheckpoint(chainID, h, nil, vals, appHash, 0, 5)
+		seeds[i] = certifiers.Seed{check, vals}
+	}
+
+	// we only store valid seeds for our chain
+	other := keys.GenCheckpoint(chainID+"-other", 20, nil, seeds[0].Validators,
+		appHash, 0, 5)
+	err := p.StoreSeed(certifiers.Seed{other, seeds[0].Validators})
+	assert.NotNil(err)
+	err = p.StoreSeed(certifiers.Seed{Validators: seeds[0].Validators})
+	assert.NotNil(err)
+
+	// check provider is empty
+	_, err = p.GetByHeight(20)
+	require.NotNil(err)
+	assert.True(certifiers.IsSeedNotFoundErr(err))
+
+	_, err = p.GetByHash(seeds[3].Hash())
+	require.NotNil(err)
+	assert.True(certifiers.IsSeedNotFoundErr(err))
+
+	// store the first one alone, the rest in a batch
+	err = p.StoreSeed(seeds[0])
+	require.Nil(err, "%+v", err)
+	err = p.StoreSeeds(seeds[1:]...)
+	require.Nil(err, "%+v", err)
+
+	// make sure we get them all back
+	for _, s := range seeds {
+		s2, err := p.GetByHeight(s.Height())
+		require.Nil(err, "%+v", err)
+		assert.Equal(s.Height(), s2.Height())
+		assert.Nil(checkEqual(s, s2, chainID), "%d", s.Height())
+	}
+
+	// by hash we get the last seed with those validators
+	seed, err := p.GetByHash(seeds[2].Hash())
+	require.Nil(err, "%+v", err)
+	assert.Equal(seeds[3].Height(), seed.Height())
+	assert.Nil(checkEqual(seeds[3], seed, chainID))
+
+	// below the first seed there is nothing
+	_, err = p.GetByHeight(19)
+	assert.True(certifiers.IsSeedNotFoundErr(err))
+
+	// make sure we get the last one if we overstep
+	seed, err = p.GetByHeight(5000)
+	if assert.Nil(err) {
+		assert.Equal(seeds[count-1].Height(), seed.Height())
+	}
+
+	// and middle ones as well
+	seed, err = p.GetByHeight(47)
+	if assert.Nil(err) {
+		// we only step by 10, so 40 must be the one below this
+		assert.Equal(40, seed.Height())
+		assert.Nil(checkEqual(seeds[2], seed, chainID))
+	}
+
+	// storing an older seed again doesn't hide the latest one by hash
+	require.Nil(p.StoreSeed(seeds[2]))
+	seed, err = p.GetByHash(seeds[2].Hash())
+	require.Nil(err, "%+v", err)
+	assert.Equal(seeds[3].Height(), seed.Height())
+
+	// deleting the latest one falls back to the one before
+	require.Nil(p.DeleteSeed(seeds[3]))
+	seed, err = p.GetByHash(seeds[3].Hash())
+	require.Nil(err, "%+v", err)
+	assert.Equal(seeds[2].Height(), seed.Height())
+	seed, err = p.GetByHeight(seeds[3].Height())
+	require.Nil(err, "%+v", err)
+	assert.Equal(seeds[2].Height(), seed.Height())
+
+	// and once they are all gone, so are the validators
+	require.Nil(p.DeleteSeed(seeds[2]))
+	_, err = p.GetByHash(seeds[2].Hash())
+	assert.True(certifiers.IsSeedNotFoundErr(err))
+	all, err := p.AllSeeds()
+	require.Nil(err, "%+v", err)
+	assert.Equal(count-2, len(all))
+
+	// a different seed at the same height replaces the old one
+	vals := keys.ToValidators(20, 0)
+	check := keys.GenCheckpoint(chainID, seeds[2].Height(), nil, vals, appHash, 0, 5)
+	require.Nil(p.StoreSeed(certifiers.Seed{check, vals}))
+	seed, err = p.GetByHash(vals.Hash())
+	require.Nil(err, "%+v", err)
+	assert.Equal(seeds[2].Height(), seed.Height())
+
+	// put them back for the callers
+	require.Nil(p.StoreSeeds(seeds[2], seeds[3]))
+	_, err = p.GetByHash(vals.Hash())
+	assert.True(certifiers.IsSeedNotFoundErr(err))
+	seed, err = p.GetByHeight(seeds[2].Height())
+	require.Nil(err, "%+v", err)
+	assert.Nil(checkEqual(seeds[2], seed, chainID))
+}
+
+func checkEqual(stored, loaded certifiers.Seed, chainID string) error {
+	err := loaded.ValidateBasic(chainID)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(stored.Hash(), loaded.Hash()) {
+		return errors.New("Different block hashes")
+	}
+	return nil
+}
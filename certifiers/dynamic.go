@@ -1,8 +1,12 @@
 package certifiers
 
 import (
+	"bytes"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/pkg/errors"
 	lc "github.com/tendermint/light-client"
 	"github.com/tendermint/tendermint/types"
 )
@@ -12,28 +16,141 @@ var _ lc.Certifier = &DynamicCertifier{}
 // DynamicCertifier uses a StaticCertifier to evaluate the checkpoint
 // but allows for a change, if we present enough proof
 //
+// It is safe for concurrent use, as long as the exported fields are
+// only set before that.
+//
 // TODO: do we keep a long history so we can use our memory to validate
 // checkpoints from previously valid validator sets????
 type DynamicCertifier struct {
+	mtx        sync.RWMutex
 	Cert       *StaticCertifier
 	LastHeight int
+	// TrustLevel is the portion of our validator set that must sign a
+	// new header for us to accept a new validator set.  If unset, we use
+	// VerifyCommitAny, which also requires the known validators to make
+	// up over 2/3 of the new set.
+	TrustLevel Fraction
+	// LastTime is the time of the last header we certified
+	LastTime time.Time
+	// TrustingPeriod is how long we trust a validator set after the last
+	// header it signed (should be less than the unbonding period).
+	// Zero means we never expire.
+	TrustingPeriod time.Duration
+	Clock          Clock
+	// LastHash is the hash of the last header we certified
+	LastHash []byte
+	// UpdateHeight is the height of the header we got our current
+	// validator set from, either a trusted seed or the last Update
+	UpdateHeight int
+	// Store persists our state after every change (optional)
+	Store StateStore
 }
 
 func NewDynamic(chainID string, vals *types.ValidatorSet) *DynamicCertifier {
 	return &DynamicCertifier{
 		Cert:       NewStatic(chainID, vals),
 		LastHeight: 0,
+		Clock:      SystemClock,
 	}
 }
 
+// Expired returns true if our validator set is older than the trusting
+// period, and may have unbonded already.  Once expired, the only safe
+// thing to do is to get a new seed from a trusted source.
+func (c *DynamicCertifier) Expired() bool {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.expired()
+}
+
+func (c *DynamicCertifier) expired() bool {
+	if c.TrustingPeriod == 0 || c.LastTime.IsZero() {
+		return false
+	}
+	return c.Clock.Now().Sub(c.LastTime) > c.TrustingPeriod
+}
+
 // Certify handles this with
 func (c *DynamicCertifier) Certify(check lc.Checkpoint) error {
-	err := c.Cert.Certify(check)
-	if err == nil {
-		// update last seen height if input is valid
+	// verify without holding the lock, so we can certify in parallel
+	c.mtx.RLock()
+	cert, expired := c.Cert, c.expired()
+	c.mtx.RUnlock()
+	if expired {
+		return ErrSeedExpired()
+	}
+	err := cert.Certify(check)
+	if err != nil {
+		return err
+	}
+
+	// most checkpoints are old news, so don't block anyone for them
+	c.mtx.RLock()
+	changed := c.advances(check)
+	c.mtx.RUnlock()
+	if !changed {
+		return nil
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.advances(check) {
+		return nil
+	}
+	// update last seen height if input is valid, never go back
+	if check.Height() > c.LastHeight {
 		c.LastHeight = check.Height()
+		c.LastHash = check.Header.Hash()
+	}
+	if check.Header.Time.After(c.LastTime) {
+		c.LastTime = check.Header.Time
+	}
+	return c.saveState()
+}
+
+// advances is true if this checkpoint would change our state
+func (c *DynamicCertifier) advances(check lc.Checkpoint) bool {
+	return check.Height() > c.LastHeight || check.Header.Time.After(c.LastTime)
+}
+
+// State returns what we need to remember about the certifier
+func (c *DynamicCertifier) State() State {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.state()
+}
+
+func (c *DynamicCertifier) state() State {
+	return State{
+		Height:       c.LastHeight,
+		Hash:         c.LastHash,
+		Time:         c.LastTime,
+		UpdateHeight: c.UpdateHeight,
+	}
+}
+
+// Restore loads a State saved earlier, unless we already know of
+// something more recent
+func (c *DynamicCertifier) Restore(state State) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if state.Height > c.LastHeight {
+		c.LastHeight = state.Height
+		c.LastHash = state.Hash
+	}
+	if state.Time.After(c.LastTime) {
+		c.LastTime = state.Time
+	}
+	if state.UpdateHeight > c.UpdateHeight {
+		c.UpdateHeight = state.UpdateHeight
 	}
-	return err
+}
+
+func (c *DynamicCertifier) saveState() error {
+	if c.Store == nil {
+		return nil
+	}
+	return c.Store.SaveState(c.state())
 }
 
 // Update will verify if this is a valid change and update
@@ -41,30 +158,75 @@ func (c *DynamicCertifier) Certify(check lc.Checkpoint) error {
 //
 // Returns an error if update is impossible (invalid proof or IsTooMuchChangeErr)
 func (c *DynamicCertifier) Update(check lc.Checkpoint, vset *types.ValidatorSet) error {
-	// ignore all checkpoints in the past -> only to the future
-	if check.Height() <= c.LastHeight {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	// ignore all checkpoints before our validators -> only to the future
+	if check.Height() <= c.UpdateHeight {
 		return ErrPastTime()
 	}
 
+	// we cannot trust old validators to vouch for anyone
+	if c.expired() {
+		return ErrSeedExpired()
+	}
+
 	// first, verify if the input is self-consistent....
 	err := check.ValidateBasic(c.Cert.ChainID)
 	if err != nil {
 		return err
 	}
 
-	// TODO: now, make sure not too much change... meaning this commit
+	// the validators must be those referred to by the header
+	if !bytes.Equal(vset.Hash(), check.Header.ValidatorsHash) {
+		return errors.Errorf("Validators %X don't match header %X",
+			vset.Hash(), check.Header.ValidatorsHash)
+	}
+
+	// now, make sure not too much change... meaning this commit
 	// would be approved by the currently known validator set
 	// as well as the new set
-	err = VerifyCommitAny(c.Cert.VSet, vset, c.Cert.ChainID,
-		check.Commit.BlockID, check.Header.Height, check.Commit)
+	err = c.verifyChange(check, vset)
 	if err != nil {
 		return ErrTooMuchChange()
 	}
 
-	// looks good, we can update
+	// looks good, we can update (certified headers may be newer already)
 	c.Cert = NewStatic(c.Cert.ChainID, vset)
-	c.LastHeight = check.Height()
-	return nil
+	c.UpdateHeight = check.Height()
+	if check.Height() > c.LastHeight {
+		c.LastHeight = check.Height()
+		c.LastHash = check.Header.Hash()
+	}
+	if check.Header.Time.After(c.LastTime) {
+		c.LastTime = check.Header.Time
+	}
+	return c.saveState()
+}
+
+// Validators returns the validator set we currently trust
+func (c *DynamicCertifier) Validators() *types.ValidatorSet {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.Cert.VSet
+}
+
+// checkChange is verifyChange for use without holding the lock
+func (c *DynamicCertifier) checkChange(check lc.Checkpoint, vset *types.ValidatorSet) error {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.verifyChange(check, vset)
+}
+
+// verifyChange checks if our validators would accept vset signing this
+// checkpoint, using our TrustLevel
+func (c *DynamicCertifier) verifyChange(check lc.Checkpoint, vset *types.ValidatorSet) error {
+	if c.TrustLevel.IsZero() {
+		return VerifyCommitAny(c.Cert.VSet, vset, c.Cert.ChainID,
+			check.Commit.BlockID, check.Header.Height, check.Commit)
+	}
+	return VerifyCommitTrusting(c.Cert.VSet, vset, c.Cert.ChainID,
+		check.Commit.BlockID, check.Header.Height, check.Commit, c.TrustLevel)
 }
 
 // VerifyCommitAny will check to see if the set would
@@ -86,33 +248,15 @@ func (c *DynamicCertifier) Update(check lc.Checkpoint, vset *types.ValidatorSet)
 func VerifyCommitAny(old, cur *types.ValidatorSet, chainID string,
 	blockID types.BlockID, height int, commit *types.Commit) error {
 
-	if cur.Size() != len(commit.Precommits) {
-		return fmt.Errorf("Invalid commit -- wrong set size: %v vs %v", cur.Size(), len(commit.Precommits))
-	}
-	if height != commit.Height() {
-		return fmt.Errorf("Invalid commit -- wrong height: %v vs %v", height, commit.Height())
+	err := checkPrecommits(cur, height, commit)
+	if err != nil {
+		return err
 	}
 
-	oldVotingPower := int64(0)
-	curVotingPower := int64(0)
+	checks := []sigCheck{}
 	seen := map[int]bool{}
-	round := commit.Round()
-
 	for idx, precommit := range commit.Precommits {
-		// first check as in VerifyCommit
-		if precommit == nil {
-			continue
-		}
-		if precommit.Height != height {
-			return lc.ErrHeightMismatch(height, precommit.Height)
-		}
-		if precommit.Round != round {
-			return fmt.Errorf("Invalid commit -- wrong round: %v vs %v", round, precommit.Round)
-		}
-		if precommit.Type != types.VoteTypePrecommit {
-			return fmt.Errorf("Invalid commit -- not precommit @ index %v", idx)
-		}
-		if !blockID.Equals(precommit.BlockID) {
+		if precommit == nil || !blockID.Equals(precommit.BlockID) {
 			continue // Not an error, but doesn't count
 		}
 
@@ -123,22 +267,28 @@ func VerifyCommitAny(old, cur *types.ValidatorSet, chainID string,
 		}
 		seen[vi] = true
 
-		// Validate signature old school
-		precommitSignBytes := types.SignBytes(chainID, precommit)
-		if !ov.PubKey.VerifyBytes(precommitSignBytes, precommit.Signature) {
-			return fmt.Errorf("Invalid commit -- invalid signature: %v", precommit)
-		}
-		// Good precommit!
-		oldVotingPower += ov.VotingPower
-
-		// check new school
+		// check new school, it only counts if properly set in the
+		// current block as well
 		_, cv := cur.GetByIndex(idx)
-		if cv.PubKey.Equals(ov.PubKey) {
-			// make sure this is properly set in the current block as well
-			curVotingPower += cv.VotingPower
+		if !cv.PubKey.Equals(ov.PubKey) {
+			cv = nil
 		}
+		checks = append(checks, sigCheck{ov, cv, precommit})
 	}
 
+	// validate signatures old school, until we have enough power in both
+	oldVotingPower := int64(0)
+	curVotingPower := int64(0)
+	oldNeeded := old.TotalVotingPower() * 2 / 3
+	curNeeded := cur.TotalVotingPower() * 2 / 3
+	verifySignatures(chainID, checks, func(c sigCheck) bool {
+		oldVotingPower += c.val.VotingPower
+		if c.cur != nil {
+			curVotingPower += c.cur.VotingPower
+		}
+		return oldVotingPower > oldNeeded && curVotingPower > curNeeded
+	})
+
 	if oldVotingPower <= old.TotalVotingPower()*2/3 {
 		return fmt.Errorf("Invalid commit -- insufficient old voting power: got %v, needed %v",
 			oldVotingPower, (old.TotalVotingPower()*2/3 + 1))
@@ -148,3 +298,58 @@ func VerifyCommitAny(old, cur *types.ValidatorSet, chainID string,
 	}
 	return nil
 }
+
+// VerifyCommitTrusting will check to see if the set would
+// be valid with a different validator set, trusting only
+// a portion of the old set.
+//
+// cur is the validator set that signed this block
+// * over 2/3 of the power in cur must sign, as usual
+//
+// old is the validator set that we know
+// * signers from old must have more than trust of the power in old
+//
+// With trust of 1/3, as long as less than 1/3 of old is byzantine,
+// at least one honest validator we know vouches for the new set.
+func VerifyCommitTrusting(old, cur *types.ValidatorSet, chainID string,
+	blockID types.BlockID, height int, commit *types.Commit, trust Fraction) error {
+
+	err := trust.ValidateTrustLevel()
+	if err != nil {
+		return err
+	}
+
+	// the new validators must properly sign the block themselves
+	err = VerifyCommitParallel(cur, chainID, blockID, height, commit)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	oldVotingPower := int64(0)
+	seen := map[int]bool{}
+	for _, precommit := range commit.Precommits {
+		if precommit == nil || !blockID.Equals(precommit.BlockID) {
+			continue // Not an error, but doesn't count
+		}
+
+		// we only grab by address, ignoring unknown validators
+		vi, ov := old.GetByAddress(precommit.ValidatorAddress)
+		if ov == nil || seen[vi] {
+			continue // missing or double vote...
+		}
+		seen[vi] = true
+
+		// it only counts if signed with the key we know
+		if !verifyVote(ov.PubKey, chainID, precommit) {
+			continue
+		}
+		oldVotingPower += ov.VotingPower
+	}
+
+	needed := trust.Of(old.TotalVotingPower())
+	if oldVotingPower <= needed {
+		return fmt.Errorf("Invalid commit -- insufficient old voting power: got %v, needed %v",
+			oldVotingPower, needed+1)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package certifiers_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -125,3 +126,139 @@ func TestDynamicUpdate(t *testing.T) {
 		}
 	}
 }
+
+// TestDynamicTrustLevel makes sure a lower trust level allows bigger jumps
+func TestDynamicTrustLevel(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	chainID := "test-dyno-trust"
+	keys := certifiers.GenValKeys(6)
+	vals := keys.ToValidators(10, 0)
+
+	// bad trust levels are rejected
+	for _, bad := range []string{"1/4", "4/3", "1/0"} {
+		f, err := certifiers.ParseFraction(bad)
+		require.Nil(err, "%+v", err)
+		assert.NotNil(f.ValidateTrustLevel(), bad)
+	}
+	_, err := certifiers.ParseFraction("foo")
+	assert.NotNil(err)
+
+	// replace 3 of 6 known validators and add 6 more: only 3/12 of the
+	// new set is known to us, but that is half of our old power
+	keys2 := keys[3:].Extend(9)
+	vals2 := keys2.ToValidators(10, 0)
+	check := keys2.GenCheckpoint(chainID, 20, nil, vals2, []byte("foo"), 0, len(keys2))
+
+	// the default requires 2/3 of both sets
+	cert := certifiers.NewDynamic(chainID, vals)
+	err = cert.Update(check, vals2)
+	assert.True(certifiers.IsTooMuchChangeErr(err), "%+v", err)
+
+	// with trust level 2/3 of the old set only, still too much
+	cert.TrustLevel = certifiers.Fraction{2, 3}
+	err = cert.Update(check, vals2)
+	assert.True(certifiers.IsTooMuchChangeErr(err), "%+v", err)
+
+	// with 1/3 we accept
+	cert.TrustLevel = certifiers.DefaultTrustLevel
+	err = cert.Update(check, vals2)
+	require.Nil(err, "%+v", err)
+	assert.Equal(20, cert.LastHeight)
+	assert.EqualValues(vals2.Hash(), cert.Cert.Hash())
+
+	// but the new set must still sign properly (8/13 here)
+	keys3 := keys2.Extend(1)
+	vals3 := keys3.ToValidators(10, 0)
+	check = keys3.GenCheckpoint(chainID, 30, nil, vals3, []byte("foo"), 0, 8)
+	err = cert.Update(check, vals3)
+	assert.True(certifiers.IsTooMuchChangeErr(err), "%+v", err)
+
+	// and the validators must match the header
+	check = keys2.GenCheckpoint(chainID, 40, nil, vals2, []byte("foo"), 0, len(keys2))
+	err = cert.Update(check, vals3)
+	assert.NotNil(err)
+}
+
+// TestDynamicExpiry makes sure we stop trusting old validators
+func TestDynamicExpiry(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	chainID := "test-dyno-expiry"
+	keys := certifiers.GenValKeys(4)
+	vals := keys.ToValidators(10, 0)
+	cert := certifiers.NewDynamic(chainID, vals)
+
+	period := time.Hour
+	clock := certifiers.NewMockClock(time.Now())
+	cert.TrustingPeriod = period
+	cert.Clock = clock
+
+	// a fresh header is good, and sets our time
+	check := keys.GenCheckpoint(chainID, 10, nil, vals, []byte("foo"), 0, len(keys))
+	err := cert.Certify(check)
+	require.Nil(err, "%+v", err)
+	assert.Equal(check.Header.Time, cert.LastTime)
+
+	// we can still update within the trusting period
+	clock.Advance(period / 2)
+	keys2 := keys.Extend(1)
+	vals2 := keys2.ToValidators(10, 0)
+	check = keys2.GenCheckpoint(chainID, 20, nil, vals2, []byte("foo"), 0, len(keys2))
+	err = cert.Update(check, vals2)
+	require.Nil(err, "%+v", err)
+	assert.False(cert.Expired())
+
+	// but once it is too old, we refuse everything
+	clock.Advance(2 * period)
+	assert.True(cert.Expired())
+	keys3 := keys2.Extend(1)
+	vals3 := keys3.ToValidators(10, 0)
+	check = keys3.GenCheckpoint(chainID, 30, nil, vals3, []byte("foo"), 0, len(keys3))
+	err = cert.Update(check, vals3)
+	assert.True(certifiers.IsSeedExpiredErr(err), "%+v", err)
+	check = keys2.GenCheckpoint(chainID, 40, nil, vals2, []byte("foo"), 0, len(keys2))
+	err = cert.Certify(check)
+	assert.True(certifiers.IsSeedExpiredErr(err), "%+v", err)
+	assert.Equal(20, cert.LastHeight)
+}
+
+// countingStore counts how often we save the state
+type countingStore struct {
+	saves int
+}
+
+func (s *countingStore) LoadState() (certifiers.State, error) {
+	return certifiers.State{}, nil
+}
+
+func (s *countingStore) SaveState(certifiers.State) error {
+	s.saves++
+	return nil
+}
+
+func TestDynamicSavesOnChange(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	chainID := "test-save"
+	keys := certifiers.GenValKeys(4)
+	vals := keys.ToValidators(10, 0)
+	cert := certifiers.NewDynamic(chainID, vals)
+	store := &countingStore{}
+	cert.Store = store
+
+	// old is older in height and time
+	old := keys.GenCheckpoint(chainID, 5, nil, vals, []byte("x"), 0, len(keys))
+	check := keys.GenCheckpoint(chainID, 10, nil, vals, []byte("x"), 0, len(keys))
+	require.Nil(cert.Certify(check))
+	assert.Equal(1, store.saves)
+
+	// nothing new, nothing to write
+	require.Nil(cert.Certify(check))
+	require.Nil(cert.Certify(old))
+	assert.Equal(1, store.saves)
+
+	next := keys.GenCheckpoint(chainID, 11, nil, vals, []byte("x"), 0, len(keys))
+	require.Nil(cert.Certify(next))
+	assert.Equal(2, store.saves)
+}
@@ -12,6 +12,7 @@ var (
 	errTooMuchChange     = rawerr.New("Validators change too much to safely update")
 	errPastTime          = rawerr.New("Update older than certifier height")
 	errNoPathFound       = rawerr.New("Cannot find a path of validators")
+	errSeedExpired       = rawerr.New("Seed is older than the trusting period")
 )
 
 // IsSeedNotFoundErr checks whether an error is due to missing data
@@ -60,3 +61,13 @@ func IsNoPathFoundErr(err error) bool {
 func ErrNoPathFound() error {
 	return errors.WithStack(errNoPathFound)
 }
+
+// IsSeedExpiredErr checks whether an error is due to a trusted seed
+// being too old to safely update from
+func IsSeedExpiredErr(err error) bool {
+	return err != nil && (errors.Cause(err) == errSeedExpired)
+}
+
+func ErrSeedExpired() error {
+	return errors.WithStack(errSeedExpired)
+}
@@ -0,0 +1,118 @@
+package certifiers
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	crypto "github.com/tendermint/go-crypto"
+	wire "github.com/tendermint/go-wire"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/tendermint/types"
+)
+
+var _ lc.Value = DuplicateVoteEvidence{}
+
+// DuplicateVoteEvidence shows that one validator signed two different
+// blocks at the same height and round, which is never allowed.
+//
+// It implements lc.Value, so it can be posted to the chain directly.
+type DuplicateVoteEvidence struct {
+	PubKey crypto.PubKey `json:"pub_key"`
+	VoteA  *types.Vote   `json:"vote_a"`
+	VoteB  *types.Vote   `json:"vote_b"`
+}
+
+// Bytes returns the go-wire encoding, to post to the chain
+func (e DuplicateVoteEvidence) Bytes() []byte {
+	return wire.BinaryBytes(e)
+}
+
+// Address of the misbehaving validator
+func (e DuplicateVoteEvidence) Address() []byte {
+	return e.PubKey.Address()
+}
+
+// Verify makes sure this is really the same validator signing two
+// conflicting votes on this chain
+func (e DuplicateVoteEvidence) Verify(chainID string) error {
+	a, b := e.VoteA, e.VoteB
+	if a == nil || b == nil {
+		return errors.New("Evidence missing votes")
+	}
+	if a.Height != b.Height || a.Round != b.Round || a.Type != b.Type {
+		return errors.Errorf("Votes at different steps: %d/%d/%d vs %d/%d/%d",
+			a.Height, a.Round, a.Type, b.Height, b.Round, b.Type)
+	}
+	if a.BlockID.Equals(b.BlockID) {
+		return errors.New("Votes for the same block")
+	}
+
+	addr := e.Address()
+	for _, vote := range []*types.Vote{a, b} {
+		if !bytes.Equal(vote.ValidatorAddress, addr) {
+			return errors.Errorf("Vote from %X, not %X", vote.ValidatorAddress, addr)
+		}
+		if !e.PubKey.VerifyBytes(types.SignBytes(chainID, vote), vote.Signature) {
+			return errors.Errorf("Invalid signature from %X", addr)
+		}
+	}
+	return nil
+}
+
+// FindDuplicateVotes compares two conflicting checkpoints at the same
+// height, and returns evidence for every validator in vals that signed both
+// in the same round.
+//
+// Both checkpoints should be certified before calling this.
+func FindDuplicateVotes(chainID string, a, b lc.Checkpoint,
+	vals *types.ValidatorSet) ([]DuplicateVoteEvidence, error) {
+
+	if a.Height() != b.Height() {
+		return nil, lc.ErrHeightMismatch(a.Height(), b.Height())
+	}
+	if a.Commit == nil || b.Commit == nil {
+		return nil, errors.New("Checkpoint missing commits")
+	}
+	if a.Commit.BlockID.Equals(b.Commit.BlockID) {
+		return nil, errors.New("Checkpoints do not conflict")
+	}
+
+	votes := map[string]*types.Vote{}
+	for _, vote := range a.Commit.Precommits {
+		if vote != nil && a.Commit.BlockID.Equals(vote.BlockID) {
+			votes[string(vote.ValidatorAddress)] = vote
+		}
+	}
+
+	res := []DuplicateVoteEvidence{}
+	for _, vote := range b.Commit.Precommits {
+		if vote == nil || !b.Commit.BlockID.Equals(vote.BlockID) {
+			continue
+		}
+		other := votes[string(vote.ValidatorAddress)]
+		if other == nil || other.Round != vote.Round {
+			continue
+		}
+		_, val := vals.GetByAddress(vote.ValidatorAddress)
+		if val == nil {
+			continue
+		}
+
+		ev := DuplicateVoteEvidence{
+			PubKey: val.PubKey,
+			VoteA:  other,
+			VoteB:  vote,
+		}
+		// only report what we can prove
+		if ev.Verify(chainID) == nil {
+			res = append(res, ev)
+		}
+	}
+	return res, nil
+}
+
+// DuplicateVotes returns evidence against all validators we trust, who
+// signed both headers in this fork
+func (f ForkEvidence) DuplicateVotes(chainID string, vals *types.ValidatorSet) ([]DuplicateVoteEvidence, error) {
+	return FindDuplicateVotes(chainID, f.Primary, f.Witness, vals)
+}
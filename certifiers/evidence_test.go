@@ -0,0 +1,60 @@
+package certifiers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	wire "github.com/tendermint/go-wire"
+	"github.com/tendermint/go-wire/data"
+	"github.com/tendermint/light-client/certifiers"
+)
+
+func TestDuplicateVotes(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	chainID := "evidence"
+	keys := certifiers.GenValKeys(5)
+	vals := keys.ToValidators(10, 0)
+	h := 30
+
+	// keys 1, 2, and 3 sign both blocks
+	a := keys.GenCheckpoint(chainID, h, nil, vals, []byte("a"), 0, 4)
+	b := keys.GenCheckpoint(chainID, h, nil, vals, []byte("b"), 1, 5)
+
+	evs, err := certifiers.FindDuplicateVotes(chainID, a, b, vals)
+	require.Nil(err, "%+v", err)
+	require.Equal(3, len(evs))
+	for i, ev := range evs {
+		assert.Nil(ev.Verify(chainID), "%d", i)
+		// wrong chain means wrong signatures
+		assert.NotNil(ev.Verify("other-chain"))
+
+		// make sure we can send it around
+		js, err := data.ToJSON(ev)
+		require.Nil(err, "%+v", err)
+		var loaded certifiers.DuplicateVoteEvidence
+		err = data.FromJSON(js, &loaded)
+		require.Nil(err, "%+v", err)
+		assert.Nil(loaded.Verify(chainID), "%d", i)
+
+		var read certifiers.DuplicateVoteEvidence
+		err = wire.ReadBinaryBytes(ev.Bytes(), &read)
+		require.Nil(err, "%+v", err)
+		assert.Nil(read.Verify(chainID), "%d", i)
+	}
+
+	// the same block is no evidence
+	_, err = certifiers.FindDuplicateVotes(chainID, a, a, vals)
+	assert.NotNil(err)
+
+	// nor are different heights
+	c := keys.GenCheckpoint(chainID, h+1, nil, vals, []byte("b"), 0, 5)
+	_, err = certifiers.FindDuplicateVotes(chainID, a, c, vals)
+	assert.NotNil(err)
+
+	// and we only report the validators we know
+	evs, err = certifiers.FindDuplicateVotes(chainID, a, b, keys[:2].ToValidators(10, 0))
+	require.Nil(err, "%+v", err)
+	assert.Equal(1, len(evs))
+}
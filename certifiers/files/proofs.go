@@ -0,0 +1,236 @@
+package files
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	wire "github.com/tendermint/go-wire"
+	"github.com/tendermint/go-wire/data"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/light-client/certifiers"
+)
+
+const (
+	ProofDir = "proofs"
+	// we limit stored proofs to 10MB, to not load junk into memory
+	proofLimit = 10 * 1000 * 1000
+)
+
+// StoredProof is a proof along with the checkpoint it validates against
+type StoredProof struct {
+	Type       string        `json:"type"`
+	Key        data.Bytes    `json:"key"`
+	Proof      data.Bytes    `json:"proof"`
+	Checkpoint lc.Checkpoint `json:"checkpoint"`
+}
+
+func (s StoredProof) Height() int {
+	return s.Checkpoint.Height()
+}
+
+// ProofInfo describes one stored proof
+type ProofInfo struct {
+	Type   string     `json:"type"`
+	Key    data.Bytes `json:"key"`
+	Height int        `json:"height"`
+}
+
+// ProofStore keeps all proofs we verified in the filesystem, as
+// <type>/<hex key>/<height>.tsd
+//
+// Everything is validated again when loaded: the checkpoint must be
+// signed by a validator set from our trusted seeds, and the proof must
+// match the checkpoint.  The provers are only used to unmarshal the
+// proofs, and are looked up by type.
+type ProofStore struct {
+	dir     string
+	chainID string
+	trusted certifiers.Provider
+	provers map[string]lc.Prover
+}
+
+// NewProofStore creates the dir as needed
+func NewProofStore(dir, chainID string, trusted certifiers.Provider,
+	provers map[string]lc.Prover) (ProofStore, error) {
+
+	s := ProofStore{
+		dir:     dir,
+		chainID: chainID,
+		trusted: trusted,
+		provers: provers,
+	}
+	err := os.MkdirAll(dir, dirPerm)
+	return s, errors.WithStack(err)
+}
+
+func (s ProofStore) keyDir(proofType string, key []byte) string {
+	return filepath.Join(s.dir, proofType, hex.EncodeToString(key))
+}
+
+func (s ProofStore) encodeHeight(h int) string {
+	// same padding as the checkpoints
+	return fmt.Sprintf("%012d%s", h, Ext)
+}
+
+// Put validates the proof against this certified checkpoint, and stores
+// both
+func (s ProofStore) Put(proofType string, key []byte, proof lc.Proof,
+	check lc.Checkpoint) error {
+
+	bz, err := proof.Marshal()
+	if err != nil {
+		return err
+	}
+	sp := StoredProof{
+		Type:       proofType,
+		Key:        key,
+		Proof:      bz,
+		Checkpoint: check,
+	}
+	_, err = s.validate(sp)
+	if err != nil {
+		return err
+	}
+
+	dir := s.keyDir(proofType, key)
+	err = os.MkdirAll(dir, dirPerm)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	path := filepath.Join(dir, s.encodeHeight(sp.Height()))
+	return writeAtomic(path, wire.BinaryBytes(sp))
+}
+
+// Get loads and validates the proof with the closest height <= h,
+// or the latest one for h=0
+func (s ProofStore) Get(proofType string, key []byte, h int) (lc.Proof, error) {
+	dir := s.keyDir(proofType, key)
+	heights, err := s.heights(dir)
+	if err != nil {
+		return nil, err
+	}
+	if h == 0 {
+		h = certifiers.FutureHeight
+	}
+	i := sort.SearchInts(heights, h+1)
+	if i == 0 {
+		return nil, lc.ErrNoData()
+	}
+
+	sp, err := s.load(filepath.Join(dir, s.encodeHeight(heights[i-1])))
+	if err != nil {
+		return nil, err
+	}
+	return s.validate(sp)
+}
+
+// List returns all proofs we stored, sorted by type, key and height
+func (s ProofStore) List() ([]ProofInfo, error) {
+	types, err := readDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	res := []ProofInfo{}
+	for _, t := range types {
+		keys, err := readDir(filepath.Join(s.dir, t))
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			key, err := hex.DecodeString(k)
+			if err != nil {
+				continue // not ours
+			}
+			heights, err := s.heights(filepath.Join(s.dir, t, k))
+			if err != nil {
+				return nil, err
+			}
+			for _, h := range heights {
+				res = append(res, ProofInfo{t, key, h})
+			}
+		}
+	}
+	return res, nil
+}
+
+// validate makes sure we still trust the stored proof
+func (s ProofStore) validate(sp StoredProof) (lc.Proof, error) {
+	prover, ok := s.provers[sp.Type]
+	if !ok {
+		return nil, errors.Errorf("Unknown proof type %s", sp.Type)
+	}
+	proof, err := prover.Unmarshal(sp.Proof)
+	if err != nil {
+		return nil, err
+	}
+	if proof.BlockHeight() != uint64(sp.Height()) {
+		return nil, lc.ErrHeightMismatch(int(proof.BlockHeight()), sp.Height())
+	}
+
+	check := sp.Checkpoint
+	if check.Header == nil {
+		return nil, errors.New("Stored proof has no header")
+	}
+	seed, err := s.trusted.GetByHash(check.Header.ValidatorsHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "Validators of stored proof are not trusted")
+	}
+	err = certifiers.NewStatic(s.chainID, seed.Validators).Certify(check)
+	if err != nil {
+		return nil, err
+	}
+	return proof, proof.Validate(check)
+}
+
+func (s ProofStore) load(path string) (sp StoredProof, err error) {
+	var f *os.File
+	f, err = os.Open(path)
+	if err != nil {
+		return sp, errors.WithStack(err)
+	}
+	defer f.Close()
+	var n int
+	wire.ReadBinaryPtr(&sp, f, proofLimit, &n, &err)
+	return sp, errors.WithStack(err)
+}
+
+// heights returns the heights of all proofs stored in dir, sorted
+func (s ProofStore) heights(dir string) ([]int, error) {
+	files, err := readDir(dir)
+	if os.IsNotExist(errors.Cause(err)) {
+		return nil, lc.ErrNoData()
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int, 0, len(files))
+	for _, f := range files {
+		h, err := strconv.Atoi(strings.TrimSuffix(f, Ext))
+		if err == nil {
+			res = append(res, h)
+		}
+	}
+	sort.Ints(res)
+	return res, nil
+}
+
+// readDir returns the sorted names of all entries in dir
+func readDir(dir string) ([]string, error) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	names, err := d.Readdirnames(0)
+	d.Close()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,132 @@
+package files_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	wire "github.com/tendermint/go-wire"
+	"github.com/tendermint/go-wire/data"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/light-client/certifiers"
+	"github.com/tendermint/light-client/certifiers/files"
+)
+
+// appHashProof just proves the app hash of a header
+type appHashProof struct {
+	Height uint64
+	Hash   data.Bytes
+}
+
+func (p appHashProof) Data() []byte             { return p.Hash }
+func (p appHashProof) BlockHeight() uint64      { return p.Height }
+func (p appHashProof) Marshal() ([]byte, error) { return wire.BinaryBytes(p), nil }
+func (p appHashProof) Validate(c lc.Checkpoint) error {
+	if !bytes.Equal(c.Header.AppHash, p.Hash) {
+		return errors.New("Wrong app hash")
+	}
+	return nil
+}
+
+type appHashProver struct{}
+
+func (appHashProver) Get(key []byte, h uint64) (lc.Proof, error) {
+	return nil, errors.New("Not supported")
+}
+
+func (appHashProver) Unmarshal(bz []byte) (lc.Proof, error) {
+	var p appHashProof
+	err := wire.ReadBinaryBytes(bz, &p)
+	return p, err
+}
+
+func TestProofStore(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	dir, err := ioutil.TempDir("", "proofstore-test")
+	require.Nil(err, "%+v", err)
+	defer os.RemoveAll(dir)
+
+	chainID := "test-proofs"
+	keys := certifiers.GenValKeys(4)
+	vals := keys.ToValidators(10, 0)
+	trusted := certifiers.NewMemStoreProvider()
+	root := keys.GenCheckpoint(chainID, 1, nil, vals, []byte("root"), 0, len(keys))
+	require.Nil(trusted.StoreSeed(certifiers.Seed{root, vals}))
+
+	provers := map[string]lc.Prover{"app": appHashProver{}}
+	store, err := files.NewProofStore(dir, chainID, trusted, provers)
+	require.Nil(err, "%+v", err)
+	key := []byte{0xca, 0xfe}
+
+	// nothing there yet
+	_, err = store.Get("app", key, 0)
+	assert.True(lc.IsNoDataErr(err), "%+v", err)
+
+	// store proofs at 10 and 20
+	for _, h := range []int{10, 20} {
+		appHash := []byte{byte(h)}
+		check := keys.GenCheckpoint(chainID, h, nil, vals, appHash, 0, len(keys))
+		err = store.Put("app", key, appHashProof{uint64(h), appHash}, check)
+		require.Nil(err, "%+v", err)
+	}
+
+	// we refuse to store what doesn't validate
+	check := keys.GenCheckpoint(chainID, 30, nil, vals, []byte("x"), 0, len(keys))
+	err = store.Put("app", key, appHashProof{30, []byte("y")}, check)
+	assert.NotNil(err)
+	err = store.Put("other", key, appHashProof{30, []byte("x")}, check)
+	assert.NotNil(err)
+
+	cases := []struct {
+		h, expected int
+	}{
+		{0, 20},
+		{25, 20},
+		{20, 20},
+		{15, 10},
+		{5, 0},
+	}
+	for i, tc := range cases {
+		pr, err := store.Get("app", key, tc.h)
+		if tc.expected == 0 {
+			assert.True(lc.IsNoDataErr(err), "%d: %+v", i, err)
+			continue
+		}
+		if assert.Nil(err, "%d: %+v", i, err) {
+			assert.EqualValues(tc.expected, pr.BlockHeight(), "%d", i)
+			assert.EqualValues([]byte{byte(tc.expected)}, pr.Data(), "%d", i)
+		}
+	}
+
+	// storing the same height again replaces it
+	check = keys.GenCheckpoint(chainID, 20, nil, vals, []byte{20}, 0, len(keys))
+	err = store.Put("app", key, appHashProof{20, []byte{20}}, check)
+	require.Nil(err, "%+v", err)
+
+	infos, err := store.List()
+	require.Nil(err, "%+v", err)
+	if assert.Equal(2, len(infos)) {
+		assert.Equal("app", infos[0].Type)
+		assert.EqualValues(key, infos[0].Key)
+		assert.Equal(10, infos[0].Height)
+		assert.Equal(20, infos[1].Height)
+	}
+
+	// once we no longer trust those validators, we don't trust the proofs
+	other, err := files.NewProofStore(dir, chainID, certifiers.NewMemStoreProvider(), provers)
+	require.Nil(err, "%+v", err)
+	_, err = other.Get("app", key, 0)
+	assert.NotNil(err)
+
+	// we report if we cannot create the store
+	file := filepath.Join(dir, "file")
+	require.Nil(ioutil.WriteFile(file, nil, 0644))
+	_, err = files.NewProofStore(filepath.Join(file, "proofs"), chainID, trusted, provers)
+	assert.NotNil(err)
+}
@@ -19,6 +19,8 @@ const (
 	filePerm = os.FileMode(0644)
 )
 
+var _ certifiers.BatchPruner = Provider{}
+
 // Provider stores all data in the filesystem
 // We assume the same validator hash may be reused by many different
 // headers/Checkpoints, and thus store it separately. This leaves us
@@ -95,19 +97,12 @@ func (m Provider) GetByHeight(h int) (certifiers.Seed, error) {
 // search for height, looks for a file with highest height < h
 // return certifiers.ErrSeedNotFound() if not there...
 func (m Provider) searchForHeight(h int) (string, error) {
-	d, err := os.Open(m.checkDir)
-	if err != nil {
-		return "", errors.WithStack(err)
-	}
-	files, err := d.Readdirnames(0)
-
-	d.Close()
+	files, err := m.checkFiles()
 	if err != nil {
-		return "", errors.WithStack(err)
+		return "", err
 	}
 
 	desired := m.encodeHeight(h)
-	sort.Strings(files)
 	i := sort.SearchStrings(files, desired)
 	if i == 0 {
 		return "", certifiers.ErrSeedNotFound()
@@ -121,3 +116,105 @@ func (m Provider) GetByHash(hash []byte) (certifiers.Seed, error) {
 	path := filepath.Join(m.valDir, m.encodeHash(hash))
 	return certifiers.LoadSeed(path)
 }
+
+// AllSeeds loads every checkpoint we have, sorted by height
+func (m Provider) AllSeeds() (certifiers.Seeds, error) {
+	files, err := m.checkFiles()
+	if err != nil {
+		return nil, err
+	}
+	res := make(certifiers.Seeds, 0, len(files))
+	for _, f := range files {
+		seed, err := certifiers.LoadSeed(filepath.Join(m.checkDir, f))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, seed)
+	}
+	return res, nil
+}
+
+// DeleteSeed removes the checkpoint at this height.  If the validator
+// file was a copy of this seed, we replace it with the most recent
+// remaining seed with the same validators.
+func (m Provider) DeleteSeed(seed certifiers.Seed) error {
+	return m.DeleteSeeds(certifiers.Seeds{seed})
+}
+
+// DeleteSeeds removes the checkpoints at all these heights, and then
+// replaces every validator file that was a copy of a deleted seed in
+// one pass over the remaining checkpoints.
+func (m Provider) DeleteSeeds(seeds certifiers.Seeds) error {
+	deleted := map[int]bool{}
+	for _, seed := range seeds {
+		path := filepath.Join(m.checkDir, m.encodeHeight(seed.Height()))
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.WithStack(err)
+		}
+		deleted[seed.Height()] = true
+	}
+
+	// find all validator files that pointed to a deleted seed
+	orphans := map[string]bool{}
+	for _, seed := range seeds {
+		valFile := m.encodeHash(seed.Hash())
+		if orphans[valFile] {
+			continue
+		}
+		stored, err := certifiers.LoadSeed(filepath.Join(m.valDir, valFile))
+		if certifiers.IsSeedNotFoundErr(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if deleted[stored.Height()] {
+			orphans[valFile] = true
+		}
+	}
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	files, err := m.checkFiles()
+	if err != nil {
+		return err
+	}
+	for i := len(files) - 1; i >= 0 && len(orphans) > 0; i-- {
+		s, err := certifiers.LoadSeed(filepath.Join(m.checkDir, files[i]))
+		if err != nil {
+			return err
+		}
+		valFile := m.encodeHash(s.Hash())
+		if orphans[valFile] {
+			err = s.Write(filepath.Join(m.valDir, valFile))
+			if err != nil {
+				return err
+			}
+			delete(orphans, valFile)
+		}
+	}
+	for valFile := range orphans {
+		err = os.Remove(filepath.Join(m.valDir, valFile))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// checkFiles returns the names of all checkpoint files, sorted by height
+func (m Provider) checkFiles() ([]string, error) {
+	d, err := os.Open(m.checkDir)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	files, err := d.Readdirnames(0)
+	d.Close()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
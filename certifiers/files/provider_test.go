@@ -92,3 +92,53 @@ func TestFileProvider(t *testing.T) {
 	assert.NotNil(err)
 	assert.True(certifiers.IsSeedNotFoundErr(err))
 }
+
+func TestFilePrune(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	dir, err := ioutil.TempDir("", "fileprune-test")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	p := files.NewProvider(dir)
+
+	chainID := "test-prune"
+	keys := certifiers.GenValKeys(4)
+	vals := keys.ToValidators(10, 0)
+	for _, h := range []int{10, 20, 30} {
+		check := keys.GenCheckpoint(chainID, h, nil, vals, []byte("x"), 0, len(keys))
+		require.Nil(p.StoreSeed(certifiers.Seed{check, vals}))
+	}
+	vals2 := keys.ToValidators(10, 5)
+	check := keys.GenCheckpoint(chainID, 40, nil, vals2, []byte("x"), 0, len(keys))
+	require.Nil(p.StoreSeed(certifiers.Seed{check, vals2}))
+
+	pruned, err := certifiers.Prune(p, false, certifiers.KeepValidatorChanges())
+	require.Nil(err, "%+v", err)
+	assert.Equal(2, len(pruned))
+
+	all, err := p.AllSeeds()
+	require.Nil(err, "%+v", err)
+	require.Equal(2, len(all))
+	assert.Equal(10, all[0].Height())
+	assert.Equal(40, all[1].Height())
+
+	// we still find the validators in the remaining seed
+	seed, err := p.GetByHash(vals.Hash())
+	require.Nil(err, "%+v", err)
+	assert.Equal(10, seed.Height())
+	seed, err = p.GetByHeight(25)
+	require.Nil(err, "%+v", err)
+	assert.Equal(10, seed.Height())
+
+	// and if we delete the last one, they are gone
+	pruned, err = certifiers.Prune(p, false, certifiers.KeepLast(1))
+	require.Nil(err, "%+v", err)
+	assert.Equal(1, len(pruned))
+	_, err = p.GetByHash(vals.Hash())
+	assert.True(certifiers.IsSeedNotFoundErr(err))
+	_, err = p.GetByHeight(25)
+	assert.True(certifiers.IsSeedNotFoundErr(err))
+	seed, err = p.GetByHash(vals2.Hash())
+	require.Nil(err, "%+v", err)
+	assert.Equal(40, seed.Height())
+}
@@ -0,0 +1,83 @@
+package files
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/tendermint/light-client/certifiers"
+)
+
+const (
+	StateFile = "state.json"
+)
+
+var _ certifiers.StateStore = StateStore{}
+
+// StateStore keeps the certifier state in one json file, which is
+// replaced atomically on every write, so a crash never leaves us with
+// a half-written state
+type StateStore struct {
+	path string
+}
+
+// NewStateStore stores the state in the given dir, next to the
+// directories of a Provider
+func NewStateStore(dir string) StateStore {
+	err := os.MkdirAll(dir, dirPerm)
+	if err != nil {
+		panic(err)
+	}
+	return StateStore{path: filepath.Join(dir, StateFile)}
+}
+
+// LoadState returns an empty State if nothing was saved yet
+func (s StateStore) LoadState() (state certifiers.State, err error) {
+	bz, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, errors.WithStack(err)
+	}
+	err = json.Unmarshal(bz, &state)
+	return state, errors.WithStack(err)
+}
+
+// SaveState writes to a temp file, then moves it in place
+func (s StateStore) SaveState(state certifiers.State) error {
+	bz, err := json.Marshal(state)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return writeAtomic(s.path, bz)
+}
+
+// writeAtomic writes bz to a temp file next to path, then moves it in
+// place, so readers never see a half-written file
+func writeAtomic(path string, bz []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	tmp := f.Name()
+	_, err = f.Write(bz)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, filePerm)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return errors.WithStack(err)
+}
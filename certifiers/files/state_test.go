@@ -0,0 +1,68 @@
+package files_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/light-client/certifiers"
+	"github.com/tendermint/light-client/certifiers/files"
+)
+
+func TestStateStore(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	dir, err := ioutil.TempDir("", "filestate-test")
+	require.Nil(err)
+	defer os.RemoveAll(dir)
+	store := files.NewStateStore(dir)
+
+	// nothing saved yet
+	state, err := store.LoadState()
+	require.Nil(err, "%+v", err)
+	assert.Equal(0, state.Height)
+
+	chainID := "test-state"
+	keys := certifiers.GenValKeys(4)
+	vals := keys.ToValidators(10, 0)
+	cert := certifiers.NewDynamic(chainID, vals)
+	cert.Store = store
+
+	// every certified header is persisted
+	for _, h := range []int{10, 30, 20} {
+		check := keys.GenCheckpoint(chainID, h, nil, vals, []byte("data"), 0, len(keys))
+		err = cert.Certify(check)
+		require.Nil(err, "%+v", err)
+	}
+	assert.Equal(30, cert.LastHeight)
+
+	// an update below the last certified header doesn't move us back
+	check := keys.GenCheckpoint(chainID, 25, nil, vals, []byte("update"), 0, len(keys))
+	err = cert.Update(check, vals)
+	require.Nil(err, "%+v", err)
+	assert.Equal(30, cert.LastHeight)
+	assert.Equal(25, cert.UpdateHeight)
+
+	state, err = store.LoadState()
+	require.Nil(err, "%+v", err)
+	assert.Equal(30, state.Height)
+	assert.Equal(25, state.UpdateHeight)
+	assert.EqualValues(cert.LastHash, state.Hash)
+	assert.True(cert.LastTime.Equal(state.Time))
+
+	// a fresh certifier restores it, and won't accept older updates
+	restarted := certifiers.NewDynamic(chainID, vals)
+	restarted.Restore(state)
+	assert.Equal(30, restarted.LastHeight)
+	assert.Equal(25, restarted.UpdateHeight)
+	check = keys.GenCheckpoint(chainID, 22, nil, vals, []byte("old"), 0, len(keys))
+	err = restarted.Update(check, vals)
+	assert.True(certifiers.IsPastTimeErr(err), "%+v", err)
+
+	// no temp files are left behind
+	infos, err := ioutil.ReadDir(dir)
+	require.Nil(err)
+	assert.Equal(1, len(infos))
+}
@@ -125,3 +125,36 @@ func (v ValKeys) GenCheckpoint(chainID string, height int, txs types.Txs,
 	}
 	return check
 }
+
+// GenNextCheckpoint is like GenCheckpoint, but builds on prev, linking
+// to it with LastBlockID as in a real chain
+func (v ValKeys) GenNextCheckpoint(prev lc.Checkpoint, txs types.Txs,
+	vals *types.ValidatorSet, appHash []byte, first, last int) lc.Checkpoint {
+
+	header := GenHeader(prev.Header.ChainID, prev.Height()+1, txs, vals, appHash)
+	header.LastBlockID = prev.Commit.BlockID
+	check := lc.Checkpoint{
+		Header: header,
+		Commit: v.SignHeader(header, first, last),
+	}
+	return check
+}
+
+// Test Helper: MockClock only moves when you tell it to
+type MockClock struct {
+	now time.Time
+}
+
+// NewMockClock creates a clock starting at the given time
+func NewMockClock(now time.Time) *MockClock {
+	return &MockClock{now: now}
+}
+
+func (m *MockClock) Now() time.Time {
+	return m.now
+}
+
+// Advance moves the clock forward by d
+func (m *MockClock) Advance(d time.Duration) {
+	m.now = m.now.Add(d)
+}
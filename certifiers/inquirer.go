@@ -1,14 +1,30 @@
 package certifiers
 
 import (
+	"bytes"
+	"encoding/hex"
+	"sync"
+	"time"
+
 	lc "github.com/tendermint/light-client"
 	"github.com/tendermint/tendermint/types"
 )
 
+// InquiringCertifier is safe for concurrent use.  Callers that need the
+// same validator set change share one update.
 type InquiringCertifier struct {
 	Cert         *DynamicCertifier
 	TrustedSeeds Provider // These are only properly validated data, from local system
 	SeedSource   Provider // This is a source of new info, like a node rpc, or other import method
+	// Witnesses are other sources we cross-check every certified
+	// checkpoint against, to detect forks (optional)
+	Witnesses []Provider
+
+	mtx  sync.Mutex
+	fork *ForkEvidence
+	// updates makes sure only one update runs at a time, and shares
+	// it between everyone who needs the same validators
+	updates updateGroup
 }
 
 func NewInquiring(chainID string, vals *types.ValidatorSet, trusted Provider, source Provider) *InquiringCertifier {
@@ -24,66 +40,211 @@ func (c *InquiringCertifier) ChainID() string {
 }
 
 func (c *InquiringCertifier) Certify(check lc.Checkpoint) error {
+	if fork := c.Fork(); fork != nil {
+		return ErrForkDetected(fork)
+	}
 	err := c.Cert.Certify(check)
-	if !IsValidatorsChangedErr(err) {
-		return err
+	if IsValidatorsChangedErr(err) {
+		err = c.updateToHash(check.Header.ValidatorsHash)
+		if err != nil {
+			return err
+		}
+		err = c.Cert.Certify(check)
 	}
-	err = c.updateToHash(check.Header.ValidatorsHash)
 	if err != nil {
 		return err
 	}
-	return c.Cert.Certify(check)
+	return c.crossCheck(check)
 }
 
 func (c *InquiringCertifier) Update(check lc.Checkpoint, vals *types.ValidatorSet) error {
+	if fork := c.Fork(); fork != nil {
+		return ErrForkDetected(fork)
+	}
 	err := c.Cert.Update(check, vals)
+	if err != nil {
+		return err
+	}
+	err = c.crossCheck(check)
 	if err == nil {
 		c.TrustedSeeds.StoreSeed(Seed{Checkpoint: check, Validators: vals})
 	}
 	return err
 }
 
+// Fork returns the evidence of conflicting headers, if any of the witnesses
+// ever showed us one.  Once this happens, we refuse to certify anything.
+func (c *InquiringCertifier) Fork() *ForkEvidence {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.fork
+}
+
+// SetTrustLevel sets the portion of the known validators that must sign
+// a new validator set to accept it.  See VerifyCommitTrusting.
+func (c *InquiringCertifier) SetTrustLevel(level Fraction) error {
+	err := level.ValidateTrustLevel()
+	if err == nil {
+		c.Cert.TrustLevel = level
+	}
+	return err
+}
+
+// SetTrustingPeriod makes us reject updates once the last header we
+// certified is older than period, according to the clock.
+// If clock is nil, we use the SystemClock.
+func (c *InquiringCertifier) SetTrustingPeriod(period time.Duration, clock Clock) {
+	if clock == nil {
+		clock = SystemClock
+	}
+	c.Cert.TrustingPeriod = period
+	c.Cert.Clock = clock
+}
+
+// UpdateStats reports the work needed to update to a new validator set
+type UpdateStats struct {
+	Fetched int // number of seeds downloaded from the SeedSource
+	Stored  int // number of seeds verified and stored in TrustedSeeds
+}
+
+// UpdateToHeight securely updates the certifier to the validator set at
+// height h, downloading as few intermediate seeds as possible.
+func (c *InquiringCertifier) UpdateToHeight(h int) (UpdateStats, error) {
+	c.updates.lock()
+	defer c.updates.unlock()
+
+	b := newBisector(c)
+	seed, err := b.fetch(h)
+	if err == nil {
+		err = b.updateTo(seed)
+	}
+	return b.stats, err
+}
+
 // updateToHash gets the validator hash we want to update to
 // if IsTooMuchChangeErr, we try to find a path by binary search over height
+//
+// If another caller is already updating to the same hash, we wait for
+// that update instead of doing it again.
 func (c *InquiringCertifier) updateToHash(vhash []byte) error {
-	// try to get the match, and update
-	seed, err := c.SeedSource.GetByHash(vhash)
-	if err != nil {
-		return err
+	return c.updates.do(hex.EncodeToString(vhash), func() error {
+		// someone else may have gotten there first
+		if bytes.Equal(c.Cert.Validators().Hash(), vhash) {
+			return nil
+		}
+
+		// try to get the match, and update
+		seed, err := c.SeedSource.GetByHash(vhash)
+		if err != nil {
+			return err
+		}
+		return newBisector(c).updateTo(seed)
+	})
+}
+
+// updateGroup runs one update at a time, and lets concurrent callers with
+// the same key share the result (like golang.org/x/sync/singleflight)
+type updateGroup struct {
+	run   sync.Mutex
+	mtx   sync.Mutex
+	calls map[string]*updateCall
+}
+
+type updateCall struct {
+	done chan struct{}
+	err  error
+}
+
+func (g *updateGroup) lock()   { g.run.Lock() }
+func (g *updateGroup) unlock() { g.run.Unlock() }
+
+// do runs fn, unless a call with the same key is in progress, in which
+// case it waits for that one and returns its error
+func (g *updateGroup) do(key string, fn func() error) error {
+	g.mtx.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mtx.Unlock()
+		<-call.done
+		return call.err
 	}
-	err = c.Cert.Update(seed.Checkpoint, seed.Validators)
-	// handle IsTooMuchChangeErr by using divide and conquer
-	if IsTooMuchChangeErr(err) {
-		err = c.updateToHeight(seed.Height())
+	if g.calls == nil {
+		g.calls = map[string]*updateCall{}
+	}
+	call := &updateCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mtx.Unlock()
+
+	g.lock()
+	call.err = fn()
+	g.unlock()
+
+	g.mtx.Lock()
+	delete(g.calls, key)
+	g.mtx.Unlock()
+	close(call.done)
+	return call.err
+}
+
+// bisector keeps track of all seeds downloaded during one update,
+// so we never have to download the same seed twice
+type bisector struct {
+	cert  *InquiringCertifier
+	seeds map[int]Seed
+	stats UpdateStats
+}
+
+func newBisector(cert *InquiringCertifier) *bisector {
+	return &bisector{
+		cert:  cert,
+		seeds: map[int]Seed{},
 	}
-	return err
 }
 
-// updateToHeight will use divide-and-conquer to find a path to h
-func (c *InquiringCertifier) updateToHeight(h int) error {
-	// try to update to this height (with checks)
-	seed, err := c.SeedSource.GetByHeight(h)
+// fetch gets the seed with highest height <= h from the source
+func (b *bisector) fetch(h int) (Seed, error) {
+	if seed, ok := b.seeds[h]; ok {
+		return seed, nil
+	}
+	seed, err := b.cert.SeedSource.GetByHeight(h)
 	if err != nil {
-		return err
+		return seed, err
 	}
-	start, end := c.Cert.LastHeight, seed.Height()
+	b.seeds[h] = seed
+	b.stats.Fetched++
+	return seed, nil
+}
+
+// updateTo will use divide-and-conquer to find a path to this seed
+//
+// If we cannot jump there directly, we first update to the seed halfway
+// between the seed of our current validators and the target and try
+// again from there.
+func (b *bisector) updateTo(seed Seed) error {
+	start, end := b.cert.Cert.State().UpdateHeight, seed.Height()
 	if end <= start {
 		return ErrNoPathFound()
 	}
-	err = c.Update(seed.Checkpoint, seed.Validators)
 
+	// try to update to this seed (with checks)
+	err := b.cert.Update(seed.Checkpoint, seed.Validators)
+	if err == nil {
+		b.stats.Stored++
+	}
 	// we can handle IsTooMuchChangeErr specially
 	if !IsTooMuchChangeErr(err) {
 		return err
 	}
 
 	// try to update to mid
-	mid := (start + end) / 2
-	err = c.updateToHeight(mid)
+	mid, err := b.fetch((start + end) / 2)
+	if err != nil {
+		return err
+	}
+	err = b.updateTo(mid)
 	if err != nil {
 		return err
 	}
 
 	// if we made it to mid, we recurse
-	return c.updateToHeight(h)
+	return b.updateTo(seed)
 }
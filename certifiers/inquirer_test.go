@@ -2,10 +2,13 @@ package certifiers_test
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	lc "github.com/tendermint/light-client"
 	"github.com/tendermint/light-client/certifiers"
 )
 
@@ -107,3 +110,166 @@ func TestInquirerMinimalPath(t *testing.T) {
 	err = cert.Certify(check)
 	assert.Nil(err, "%+v", err)
 }
+
+func TestInquirerBisectionStats(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	// set up the validators to generate test blocks
+	var vote int64 = 10
+	keys := certifiers.GenValKeys(6)
+	vals := keys.ToValidators(vote, 0)
+	chainID := "bisection-stats"
+
+	// construct a bunch of seeds, replacing one validator every time
+	count := 32
+	seeds := make([]certifiers.Seed, count)
+	for i := 0; i < count; i++ {
+		keys = keys.Change(i % len(keys))
+		vals = keys.ToValidators(vote, 0)
+		h := 10 * (i + 1)
+		appHash := []byte(fmt.Sprintf("h=%d", h))
+		cp := keys.GenCheckpoint(chainID, h, nil, vals, appHash, 0, len(keys))
+		seeds[i] = certifiers.Seed{cp, vals}
+	}
+	target := seeds[count-1].Height()
+
+	run := func(level *certifiers.Fraction) certifiers.UpdateStats {
+		trust := certifiers.NewMemStoreProvider()
+		source := certifiers.NewMemStoreProvider()
+		for _, s := range seeds {
+			require.Nil(source.StoreSeed(s))
+		}
+		cert := certifiers.NewInquiring(chainID, seeds[0].Validators, trust, source)
+		if level != nil {
+			require.Nil(cert.SetTrustLevel(*level))
+		}
+
+		stats, err := cert.UpdateToHeight(target)
+		require.Nil(err, "%+v", err)
+		assert.Equal(target, cert.Cert.LastHeight)
+		assert.True(stats.Fetched >= stats.Stored)
+
+		// everything we stored is trusted now
+		seed, err := certifiers.LatestSeed(trust)
+		require.Nil(err, "%+v", err)
+		assert.Equal(target, seed.Height())
+		return stats
+	}
+
+	// the default needs many small steps
+	strict := run(nil)
+	assert.True(strict.Stored > 1)
+
+	// trusting only 1/3 of the old set needs fewer intermediate seeds
+	loose := run(&certifiers.DefaultTrustLevel)
+	assert.True(loose.Stored < strict.Stored, "%#v vs %#v", loose, strict)
+	assert.True(loose.Fetched <= strict.Fetched, "%#v vs %#v", loose, strict)
+}
+
+// TestInquirerBisectFromSeed makes sure certifying later headers with the
+// same validators doesn't hide the seeds we need to bisect through
+func TestInquirerBisectFromSeed(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	var vote int64 = 10
+	keys := certifiers.GenValKeys(5)
+	chainID := "bisect-from-seed"
+
+	// the validators change one at a time, every 10 blocks
+	count := 20
+	seeds := make([]certifiers.Seed, count)
+	first := keys
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			keys = keys.Change(i % len(keys))
+		}
+		vals := keys.ToValidators(vote, 0)
+		h := 10 * (i + 1)
+		cp := keys.GenCheckpoint(chainID, h, nil, vals, []byte("seed"), 0, len(keys))
+		seeds[i] = certifiers.Seed{cp, vals}
+	}
+	target := seeds[count-1].Height()
+
+	trust := certifiers.NewMemStoreProvider()
+	source := certifiers.NewMemStoreProvider()
+	for _, s := range seeds {
+		require.Nil(source.StoreSeed(s))
+	}
+	cert := certifiers.NewInquiring(chainID, seeds[0].Validators, trust, source)
+	cert.Cert.UpdateHeight = seeds[0].Height()
+
+	// the old validators still sign a header past half the seeds
+	vals := first.ToValidators(vote, 0)
+	late := first.GenCheckpoint(chainID, 150, nil, vals, []byte("late"), 0, len(first))
+	require.Nil(cert.Cert.Certify(late))
+	assert.Equal(150, cert.Cert.LastHeight)
+
+	// we still find a path from the seed we trust
+	_, err := cert.UpdateToHeight(target)
+	require.Nil(err, "%+v", err)
+	state := cert.Cert.State()
+	assert.Equal(target, state.Height)
+	assert.Equal(target, state.UpdateHeight)
+}
+
+// countingProvider counts how often we look up validators by hash
+type countingProvider struct {
+	certifiers.Provider
+	byHash int32
+}
+
+func (p *countingProvider) GetByHash(hash []byte) (certifiers.Seed, error) {
+	atomic.AddInt32(&p.byHash, 1)
+	return p.Provider.GetByHash(hash)
+}
+
+func TestInquirerConcurrent(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+	trust := certifiers.NewMemStoreProvider()
+	source := &countingProvider{Provider: certifiers.NewMemStoreProvider()}
+
+	var vote int64 = 10
+	keys := certifiers.GenValKeys(5)
+	vals := keys.ToValidators(vote, 0)
+
+	chainID := "concurrent-test"
+	cert := certifiers.NewInquiring(chainID, vals, trust, source)
+
+	// the validator set grows one by one
+	count := 10
+	for i := 0; i < count; i++ {
+		keys = keys.Extend(1)
+		vals = keys.ToValidators(vote, 0)
+		h := 20 + 10*i
+		cp := keys.GenCheckpoint(chainID, h, nil, vals, []byte("grow"), 0, len(keys))
+		require.Nil(source.StoreSeed(certifiers.Seed{cp, vals}))
+	}
+
+	// many parallel requests need the same update
+	n := 20
+	checks := make([]lc.Checkpoint, n)
+	for i := range checks {
+		h := 200 + i
+		appHash := []byte(fmt.Sprintf("h=%d", h))
+		checks[i] = keys.GenCheckpoint(chainID, h, nil, vals, appHash, 0, len(keys))
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = cert.Certify(checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		assert.Nil(err, "%d: %+v", i, err)
+	}
+	// but only one of them did the work
+	assert.EqualValues(1, atomic.LoadInt32(&source.byHash))
+	assert.Equal(200+n-1, cert.Cert.State().Height)
+	assert.Equal(vals.Hash(), cert.Cert.Validators().Hash())
+}
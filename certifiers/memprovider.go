@@ -1,21 +1,60 @@
 package certifiers
 
 import (
+	"container/list"
 	"encoding/hex"
-	"sort"
+	"sync"
+
+	"github.com/google/btree"
 )
 
+var _ Pruner = (*MemStoreProvider)(nil)
+
+// MemStoreProvider keeps seeds in memory.  It is safe for concurrent use.
+//
+// Seeds are indexed by height in a btree, so range searches (nil, h] are
+// fast.  If a maximum size is set, the least recently used seeds are
+// evicted, which makes it a good first layer for a CacheProvider.
 type MemStoreProvider struct {
-	// byHeight is always sorted by Height... need to support range search (nil, h]
-	// btree would be more efficient for larger sets
-	byHeight Seeds
-	byHash   map[string]Seed
+	mtx      sync.Mutex
+	byHeight *btree.BTree
+	// byHash holds all seeds for one validator hash, keyed by height
+	byHash  map[string]map[int]*memEntry
+	lru     *list.List
+	maxSize int
+	stats   CacheStats
+}
+
+// CacheStats reports how useful a cache is
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+	Size   int
+}
+
+type memEntry struct {
+	height int
+	seed   Seed
+	elem   *list.Element
+}
+
+func (e *memEntry) Less(than btree.Item) bool {
+	return e.height < than.(*memEntry).height
 }
 
+// NewMemStoreProvider stores an unlimited amount of seeds
 func NewMemStoreProvider() *MemStoreProvider {
+	return NewBoundedMemStoreProvider(0)
+}
+
+// NewBoundedMemStoreProvider stores at most maxSize seeds, evicting the
+// least recently used.  maxSize 0 means no limit.
+func NewBoundedMemStoreProvider(maxSize int) *MemStoreProvider {
 	return &MemStoreProvider{
-		byHeight: Seeds{},
-		byHash:   map[string]Seed{},
+		byHeight: btree.New(16),
+		byHash:   map[string]map[int]*memEntry{},
+		lru:      list.New(),
+		maxSize:  maxSize,
 	}
 }
 
@@ -23,6 +62,15 @@ func (m *MemStoreProvider) encodeHash(hash []byte) string {
 	return hex.EncodeToString(hash)
 }
 
+// Stats returns the hits and misses of all lookups so far
+func (m *MemStoreProvider) Stats() CacheStats {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	stats := m.stats
+	stats.Size = m.byHeight.Len()
+	return stats
+}
+
 func (m *MemStoreProvider) StoreSeed(seed Seed) error {
 	// make sure the seed is self-consistent before saving
 	err := seed.ValidateBasic(seed.Checkpoint.Header.ChainID)
@@ -30,30 +78,97 @@ func (m *MemStoreProvider) StoreSeed(seed Seed) error {
 		return err
 	}
 
-	// store the valid seed
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	// store the valid seed, replacing any at the same height
+	m.remove(seed.Height())
+	entry := &memEntry{height: seed.Height(), seed: seed}
+	entry.elem = m.lru.PushFront(entry)
+	m.byHeight.ReplaceOrInsert(entry)
 	key := m.encodeHash(seed.Hash())
-	m.byHash[key] = seed
-	m.byHeight = append(m.byHeight, seed)
-	sort.Sort(m.byHeight)
+	if m.byHash[key] == nil {
+		m.byHash[key] = map[int]*memEntry{}
+	}
+	m.byHash[key][entry.height] = entry
+
+	// and make room if needed
+	for m.maxSize > 0 && m.byHeight.Len() > m.maxSize {
+		oldest := m.lru.Back().Value.(*memEntry)
+		m.remove(oldest.height)
+	}
 	return nil
 }
 
 func (m *MemStoreProvider) GetByHeight(h int) (Seed, error) {
-	// search from highest to lowest
-	for i := len(m.byHeight) - 1; i >= 0; i-- {
-		s := m.byHeight[i]
-		if s.Height() <= h {
-			return s, nil
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	var found *memEntry
+	m.byHeight.DescendLessOrEqual(&memEntry{height: h}, func(i btree.Item) bool {
+		found = i.(*memEntry)
+		return false
+	})
+	return m.hit(found)
+}
+
+// GetByHash returns the most recent seed with these validators
+func (m *MemStoreProvider) GetByHash(hash []byte) (Seed, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	var found *memEntry
+	for _, e := range m.byHash[m.encodeHash(hash)] {
+		if found == nil || e.height > found.height {
+			found = e
 		}
 	}
-	return Seed{}, ErrSeedNotFound()
+	return m.hit(found)
 }
 
-func (m *MemStoreProvider) GetByHash(hash []byte) (Seed, error) {
-	var err error
-	s, ok := m.byHash[m.encodeHash(hash)]
-	if !ok {
-		err = ErrSeedNotFound()
+// hit counts the lookup and marks the entry as recently used
+func (m *MemStoreProvider) hit(entry *memEntry) (Seed, error) {
+	if entry == nil {
+		m.stats.Misses++
+		return Seed{}, ErrSeedNotFound()
+	}
+	m.stats.Hits++
+	m.lru.MoveToFront(entry.elem)
+	return entry.seed, nil
+}
+
+// AllSeeds returns all seeds, sorted by height
+func (m *MemStoreProvider) AllSeeds() (Seeds, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	res := make(Seeds, 0, m.byHeight.Len())
+	m.byHeight.Ascend(func(i btree.Item) bool {
+		res = append(res, i.(*memEntry).seed)
+		return true
+	})
+	return res, nil
+}
+
+// DeleteSeed removes the seed at this height, if present
+func (m *MemStoreProvider) DeleteSeed(seed Seed) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.remove(seed.Height())
+	return nil
+}
+
+// remove drops the seed at height h from all indexes, must hold the lock
+func (m *MemStoreProvider) remove(h int) {
+	item := m.byHeight.Delete(&memEntry{height: h})
+	if item == nil {
+		return
+	}
+	entry := item.(*memEntry)
+	m.lru.Remove(entry.elem)
+	key := m.encodeHash(entry.seed.Hash())
+	delete(m.byHash[key], h)
+	if len(m.byHash[key]) == 0 {
+		delete(m.byHash, key)
 	}
-	return s, err
 }
@@ -101,11 +101,47 @@ func BenchmarkCertifyCheckpointSec100(b *testing.B) {
 	benchmarkCertifyCheckpoint(b, keys)
 }
 
+// the same, but verifying one signature after the other, to compare
+func BenchmarkCertifyCheckpointSerial100(b *testing.B) {
+	keys := certifiers.GenValKeys(100)
+	benchmarkCertifyCheckpointSerial(b, keys)
+}
+
+func BenchmarkCertifyCheckpointSecSerial100(b *testing.B) {
+	keys := certifiers.GenSecValKeys(100)
+	benchmarkCertifyCheckpointSerial(b, keys)
+}
+
+func benchmarkCertifyCheckpointSerial(b *testing.B, keys certifiers.ValKeys) {
+	defer func(w int) { certifiers.SignatureWorkers = w }(certifiers.SignatureWorkers)
+	certifiers.SignatureWorkers = 1
+	benchmarkCertifyCheckpoint(b, keys)
+}
+
+func BenchmarkVerifyCommitAnySec100(b *testing.B) {
+	keys := certifiers.GenSecValKeys(100)
+	chainID := "bench-any"
+	vals := keys.ToValidators(20, 10)
+	// one validator was replaced
+	nkeys := keys.Change(5)
+	nvals := nkeys.ToValidators(20, 10)
+	check := nkeys.GenCheckpoint(chainID, 123, nil, nvals, []byte("foo"), 0, len(nkeys))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := certifiers.VerifyCommitAny(vals, nvals, chainID,
+			check.Commit.BlockID, check.Height(), check.Commit)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
 func benchmarkCertifyCheckpoint(b *testing.B, keys certifiers.ValKeys) {
 	chainID := "bench-certify"
 	vals := keys.ToValidators(20, 10)
 	cert := certifiers.NewStatic(chainID, vals)
 	check := keys.GenCheckpoint(chainID, 123, nil, vals, []byte("foo"), 0, len(keys))
+	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		err := cert.Certify(check)
 		if err != nil {
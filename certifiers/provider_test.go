@@ -1,6 +1,7 @@
 package certifiers_test
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -126,3 +127,76 @@ func TestCacheGetsBestHeight(t *testing.T) {
 	checkGetHeight(t, p2, 99, 90)
 	checkGetHeight(t, cp, 99, 90)
 }
+
+func TestMemProviderLRU(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	chainID := "test-lru"
+	keys := certifiers.GenValKeys(4)
+	vals := keys.ToValidators(10, 0)
+	p := certifiers.NewBoundedMemStoreProvider(3)
+	// also works as the first layer of a cache
+	checkProvider(t, certifiers.NewCacheProvider(p, certifiers.NewMemStoreProvider()), chainID, "lru")
+
+	p = certifiers.NewBoundedMemStoreProvider(3)
+	for _, h := range []int{10, 20, 30} {
+		check := keys.GenCheckpoint(chainID, h, nil, vals, []byte("lru"), 0, len(keys))
+		require.Nil(p.StoreSeed(certifiers.Seed{check, vals}))
+	}
+
+	// touch 10, so 20 is the least recently used
+	_, err := p.GetByHeight(15)
+	require.Nil(err, "%+v", err)
+	check := keys.GenCheckpoint(chainID, 40, nil, vals, []byte("lru"), 0, len(keys))
+	require.Nil(p.StoreSeed(certifiers.Seed{check, vals}))
+
+	all, err := p.AllSeeds()
+	require.Nil(err)
+	if assert.Equal(3, len(all)) {
+		assert.Equal(10, all[0].Height())
+		assert.Equal(30, all[1].Height())
+		assert.Equal(40, all[2].Height())
+	}
+	seed, err := p.GetByHeight(25)
+	require.Nil(err, "%+v", err)
+	assert.Equal(10, seed.Height())
+	_, err = p.GetByHeight(5)
+	assert.True(certifiers.IsSeedNotFoundErr(err))
+
+	stats := p.Stats()
+	assert.EqualValues(2, stats.Hits)
+	assert.EqualValues(1, stats.Misses)
+	assert.Equal(3, stats.Size)
+}
+
+func TestMemProviderConcurrent(t *testing.T) {
+	chainID := "test-concurrent"
+	keys := certifiers.GenValKeys(4)
+	vals := keys.ToValidators(10, 0)
+	p := certifiers.NewBoundedMemStoreProvider(20)
+
+	seeds := make([]certifiers.Seed, 50)
+	for i := range seeds {
+		check := keys.GenCheckpoint(chainID, i+1, nil, vals, []byte("race"), 0, len(keys))
+		seeds[i] = certifiers.Seed{check, vals}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 5; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(seeds); i += 5 {
+				assert.Nil(t, p.StoreSeed(seeds[i]))
+				p.GetByHeight(i)
+				p.GetByHash(vals.Hash())
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 20, p.Stats().Size)
+	seed, err := p.GetByHash(vals.Hash())
+	require.Nil(t, err, "%+v", err)
+	assert.Equal(t, 50, seed.Height())
+}
@@ -0,0 +1,171 @@
+package certifiers
+
+import (
+	"bytes"
+	"sort"
+	"time"
+)
+
+// Pruner is an optional interface for Providers that can delete seeds,
+// so the trusted store doesn't grow forever
+type Pruner interface {
+	// AllSeeds returns every stored seed, sorted by height
+	AllSeeds() (Seeds, error)
+	// DeleteSeed removes the seed at this height
+	DeleteSeed(seed Seed) error
+}
+
+// BatchPruner is implemented by Pruners that can delete many seeds at
+// once more efficiently than one by one
+type BatchPruner interface {
+	Pruner
+	// DeleteSeeds removes all these seeds (sorted by height)
+	DeleteSeeds(seeds Seeds) error
+}
+
+// PrunePolicy selects which of these seeds (sorted by height) must be
+// kept
+type PrunePolicy func(seeds Seeds) Seeds
+
+// KeepLast keeps the n most recent seeds
+func KeepLast(n int) PrunePolicy {
+	return func(seeds Seeds) Seeds {
+		if len(seeds) <= n {
+			return seeds
+		}
+		return seeds[len(seeds)-n:]
+	}
+}
+
+// KeepValidatorChanges keeps the first seed after each change
+// of the validator set, which is all we need to update through history
+func KeepValidatorChanges() PrunePolicy {
+	return func(seeds Seeds) Seeds {
+		res := Seeds{}
+		for i, s := range seeds {
+			if i == 0 || !bytes.Equal(s.Hash(), seeds[i-1].Hash()) {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+}
+
+// KeepNewerThan keeps all seeds younger than period, as older ones
+// cannot be trusted anymore (see DynamicCertifier.TrustingPeriod).
+// If clock is nil, we use the SystemClock.
+func KeepNewerThan(period time.Duration, clock Clock) PrunePolicy {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return func(seeds Seeds) Seeds {
+		cutoff := clock.Now().Add(-period)
+		res := Seeds{}
+		for _, s := range seeds {
+			if !s.Header.Time.Before(cutoff) {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+}
+
+// Prune deletes every seed that is kept by none of the policies, and
+// returns them.  If dryRun is true, we only return what we would delete.
+// Without any policy, nothing is deleted.
+//
+// The most recent seed is never deleted, as we need it to start up.
+func Prune(p Pruner, dryRun bool, policies ...PrunePolicy) (Seeds, error) {
+	seeds, err := p.AllSeeds()
+	if err != nil || len(seeds) == 0 || len(policies) == 0 {
+		return nil, err
+	}
+
+	keep := map[int]bool{seeds[len(seeds)-1].Height(): true}
+	for _, policy := range policies {
+		for _, s := range policy(seeds) {
+			keep[s.Height()] = true
+		}
+	}
+
+	res := Seeds{}
+	for _, s := range seeds {
+		if !keep[s.Height()] {
+			res = append(res, s)
+		}
+	}
+	if dryRun || len(res) == 0 {
+		return res, nil
+	}
+	return res, deleteSeeds(p, res)
+}
+
+// deleteSeeds uses the batch delete if p supports it
+func deleteSeeds(p Pruner, seeds Seeds) error {
+	if bp, ok := p.(BatchPruner); ok {
+		return bp.DeleteSeeds(seeds)
+	}
+	for _, s := range seeds {
+		err := p.DeleteSeed(s)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ BatchPruner = CacheProvider{}
+
+// AllSeeds merges the seeds of all providers that are Pruners
+func (c CacheProvider) AllSeeds() (Seeds, error) {
+	byHeight := map[int]Seed{}
+	for _, p := range c.Providers {
+		if pr, ok := p.(Pruner); ok {
+			seeds, err := pr.AllSeeds()
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range seeds {
+				byHeight[s.Height()] = s
+			}
+		}
+	}
+
+	res := make(Seeds, 0, len(byHeight))
+	for _, s := range byHeight {
+		res = append(res, s)
+	}
+	sort.Sort(res)
+	return res, nil
+}
+
+// DeleteSeed removes the seed from all providers that are Pruners
+//
+// Aborts on first error it encounters (closest provider)
+func (c CacheProvider) DeleteSeed(seed Seed) error {
+	for _, p := range c.Providers {
+		if pr, ok := p.(Pruner); ok {
+			err := pr.DeleteSeed(seed)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteSeeds removes the seeds from all providers that are Pruners,
+// using the batch delete where available
+//
+// Aborts on first error it encounters (closest provider)
+func (c CacheProvider) DeleteSeeds(seeds Seeds) error {
+	for _, p := range c.Providers {
+		if pr, ok := p.(Pruner); ok {
+			err := deleteSeeds(pr, seeds)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package certifiers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/light-client/certifiers"
+)
+
+func TestPrune(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	chainID := "test-prune"
+	keys := certifiers.GenValKeys(4)
+	mem := certifiers.NewMemStoreProvider()
+	p := certifiers.NewCacheProvider(certifiers.NewMissingProvider(), mem)
+
+	// seeds at 10, 20, ... 80, validators change every 3 seeds
+	for i := 0; i < 8; i++ {
+		vals := keys.ToValidators(10, int64(i/3))
+		check := keys.GenCheckpoint(chainID, 10*(i+1), nil, vals, []byte("x"), 0, len(keys))
+		require.Nil(p.StoreSeed(certifiers.Seed{check, vals}))
+	}
+
+	heights := func(seeds certifiers.Seeds) []int {
+		res := []int{}
+		for _, s := range seeds {
+			res = append(res, s.Height())
+		}
+		return res
+	}
+
+	cases := []struct {
+		policies []certifiers.PrunePolicy
+		pruned   []int
+	}{
+		// nothing selected
+		{nil, []int{}},
+		{[]certifiers.PrunePolicy{certifiers.KeepLast(10)}, []int{}},
+		{[]certifiers.PrunePolicy{certifiers.KeepLast(3)}, []int{10, 20, 30, 40, 50}},
+		// the latest seed is always kept
+		{[]certifiers.PrunePolicy{certifiers.KeepLast(0)}, []int{10, 20, 30, 40, 50, 60, 70}},
+		{[]certifiers.PrunePolicy{certifiers.KeepValidatorChanges()}, []int{20, 30, 50, 60}},
+		// combined, we only delete what no policy keeps
+		{
+			[]certifiers.PrunePolicy{certifiers.KeepValidatorChanges(), certifiers.KeepLast(2)},
+			[]int{20, 30, 50, 60},
+		},
+		{
+			[]certifiers.PrunePolicy{certifiers.KeepValidatorChanges(), certifiers.KeepLast(4)},
+			[]int{20, 30},
+		},
+	}
+
+	for i, tc := range cases {
+		pruned, err := certifiers.Prune(p, true, tc.policies...)
+		require.Nil(err, "%d: %+v", i, err)
+		assert.Equal(tc.pruned, heights(pruned), "%d", i)
+	}
+
+	// only time can tell what is expired
+	clock := certifiers.NewMockClock(time.Now())
+	expired := certifiers.KeepNewerThan(time.Hour, clock)
+	pruned, err := certifiers.Prune(p, true, expired)
+	require.Nil(err, "%+v", err)
+	assert.Empty(pruned)
+	clock.Advance(2 * time.Hour)
+	pruned, err = certifiers.Prune(p, true, expired)
+	require.Nil(err, "%+v", err)
+	assert.Equal(7, len(pruned))
+
+	// dry run didn't touch anything
+	all, err := mem.AllSeeds()
+	require.Nil(err)
+	assert.Equal(8, len(all))
+
+	// now really delete them
+	pruned, err = certifiers.Prune(p, false, certifiers.KeepValidatorChanges())
+	require.Nil(err, "%+v", err)
+	assert.Equal([]int{20, 30, 50, 60}, heights(pruned))
+	all, err = p.AllSeeds()
+	require.Nil(err)
+	assert.Equal([]int{10, 40, 70, 80}, heights(all))
+
+	// lookups skip the holes, and find the remaining seeds by hash
+	seed, err := p.GetByHeight(35)
+	require.Nil(err, "%+v", err)
+	assert.Equal(10, seed.Height())
+	seed, err = p.GetByHash(all[1].Hash())
+	require.Nil(err, "%+v", err)
+	assert.Equal(40, seed.Height())
+	seed, err = p.GetByHash(all[2].Hash())
+	require.Nil(err, "%+v", err)
+	assert.Equal(80, seed.Height())
+}
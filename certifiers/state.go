@@ -0,0 +1,26 @@
+package certifiers
+
+import (
+	"time"
+
+	"github.com/tendermint/go-wire/data"
+)
+
+// State is what a DynamicCertifier must remember between restarts,
+// so it never accepts anything from before the last certified header
+//
+// UpdateHeight is where we got our validators from, which is where
+// we start looking for a path to a new validator set.
+type State struct {
+	Height       int        `json:"height"`
+	Hash         data.Bytes `json:"hash"`
+	Time         time.Time  `json:"time"`
+	UpdateHeight int        `json:"update_height"`
+}
+
+// StateStore persists the State of a certifier
+type StateStore interface {
+	// LoadState returns an empty State if nothing was saved yet
+	LoadState() (State, error)
+	SaveState(State) error
+}
@@ -22,15 +22,22 @@ type StaticCertifier struct {
 }
 
 func NewStatic(chainID string, vals *types.ValidatorSet) *StaticCertifier {
+	var vhash []byte
+	if vals != nil {
+		vhash = vals.Hash()
+	}
 	return &StaticCertifier{
 		ChainID: chainID,
 		VSet:    vals,
+		vhash:   vhash,
 	}
 }
 
+// Hash is computed up front by NewStatic, so it is safe to call
+// concurrently
 func (c *StaticCertifier) Hash() []byte {
 	if len(c.vhash) == 0 {
-		c.vhash = c.VSet.Hash()
+		return c.VSet.Hash()
 	}
 	return c.vhash
 }
@@ -48,7 +55,7 @@ func (c *StaticCertifier) Certify(check lc.Checkpoint) error {
 	}
 
 	// then make sure we have the proper signatures for this
-	err = c.VSet.VerifyCommit(c.ChainID, check.Commit.BlockID,
+	err = VerifyCommitParallel(c.VSet, c.ChainID, check.Commit.BlockID,
 		check.Header.Height, check.Commit)
 	return errors.WithStack(err)
 }
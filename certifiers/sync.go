@@ -0,0 +1,93 @@
+package certifiers
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// SyncStats reports the work done by SyncToHeight
+type SyncStats struct {
+	Headers int // number of headers downloaded and verified
+	Stored  int // number of seeds stored in TrustedSeeds
+}
+
+// SyncToHeight downloads every header from our last trusted seed up
+// to h from the SeedSource, and makes sure each one links to the one
+// before with LastBlockID.  Unlike UpdateToHeight, this verifies the
+// full header chain, even the part we only certified by signatures.
+//
+// Every interval heights (and at h), we store the seed in TrustedSeeds.
+// If interval is 0, we only store h and changes of the validator set.
+func (c *InquiringCertifier) SyncToHeight(h, interval int) (SyncStats, error) {
+	var stats SyncStats
+	if fork := c.Fork(); fork != nil {
+		return stats, ErrForkDetected(fork)
+	}
+
+	// make sure nobody updates the validators while we are at it
+	c.updates.lock()
+	defer c.updates.unlock()
+
+	state := c.Cert.State()
+	if h <= state.Height {
+		return stats, ErrPastTime()
+	}
+
+	// the chain must link back to a header we already trust
+	trusted, err := c.TrustedSeeds.GetByHeight(state.Height)
+	if err != nil {
+		return stats, err
+	}
+	prev := trusted.Header.Hash()
+
+	for height := trusted.Height() + 1; height <= h; height++ {
+		seed, err := c.fetchHeader(height)
+		if err != nil {
+			return stats, err
+		}
+		stats.Headers++
+
+		// must follow the last header we trust
+		if !bytes.Equal(seed.Header.LastBlockID.Hash, prev) {
+			return stats, errors.Errorf("Header %d links to %X, not %X",
+				height, seed.Header.LastBlockID.Hash, prev)
+		}
+
+		// we can follow validator changes block by block
+		changed := !bytes.Equal(seed.Hash(), c.Cert.Validators().Hash())
+		if changed {
+			err = c.Cert.Update(seed.Checkpoint, seed.Validators)
+		} else {
+			err = c.Cert.Certify(seed.Checkpoint)
+		}
+		if err == nil {
+			err = c.crossCheck(seed.Checkpoint)
+		}
+		if err != nil {
+			return stats, err
+		}
+		prev = seed.Header.Hash()
+
+		if changed || height == h || (interval > 0 && height%interval == 0) {
+			err = c.TrustedSeeds.StoreSeed(seed)
+			if err != nil {
+				return stats, err
+			}
+			stats.Stored++
+		}
+	}
+	return stats, nil
+}
+
+// fetchHeader gets the seed at exactly this height from the SeedSource
+func (c *InquiringCertifier) fetchHeader(h int) (Seed, error) {
+	seed, err := c.SeedSource.GetByHeight(h)
+	if err != nil {
+		return seed, err
+	}
+	if seed.Height() != h {
+		return seed, errors.Errorf("Missing header %d in source", h)
+	}
+	return seed, seed.ValidateBasic(c.ChainID())
+}
@@ -0,0 +1,77 @@
+package certifiers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/light-client/certifiers"
+)
+
+func TestSyncToHeight(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+	trust := certifiers.NewMemStoreProvider()
+	source := certifiers.NewMemStoreProvider()
+
+	chainID := "sync-test"
+	keys := certifiers.GenValKeys(5)
+	vals := keys.ToValidators(10, 0)
+	cert := certifiers.NewInquiring(chainID, vals, trust, source)
+
+	// build a proper chain, validators change at height 10
+	check := keys.GenCheckpoint(chainID, 1, nil, vals, []byte("sync"), 0, len(keys))
+	root := certifiers.Seed{check, vals}
+	require.Nil(source.StoreSeed(root))
+	require.Nil(cert.Certify(check))
+	for h := 2; h <= 20; h++ {
+		if h == 10 {
+			keys = keys.Extend(1)
+			vals = keys.ToValidators(10, 0)
+		}
+		check = keys.GenNextCheckpoint(check, nil, vals, []byte("sync"), 0, len(keys))
+		require.Nil(source.StoreSeed(certifiers.Seed{check, vals}))
+	}
+
+	// we need a trusted seed to start from
+	_, err := cert.SyncToHeight(20, 5)
+	assert.True(certifiers.IsSeedNotFoundErr(err), "%+v", err)
+	require.Nil(trust.StoreSeed(root))
+
+	// headers we only certified by signature are linked as well
+	seven, err := source.GetByHeight(7)
+	require.Nil(err, "%+v", err)
+	require.Nil(cert.Certify(seven.Checkpoint))
+	assert.Equal(7, cert.Cert.State().Height)
+
+	stats, err := cert.SyncToHeight(20, 5)
+	require.Nil(err, "%+v", err)
+	assert.Equal(19, stats.Headers)
+	assert.Equal(4, stats.Stored)
+	assert.Equal(20, cert.Cert.State().Height)
+	assert.Equal(vals.Hash(), cert.Cert.Validators().Hash())
+	all, err := trust.AllSeeds()
+	require.Nil(err)
+	heights := []int{}
+	for _, s := range all {
+		heights = append(heights, s.Height())
+	}
+	assert.Equal([]int{1, 5, 10, 15, 20}, heights)
+
+	// can't sync backwards
+	_, err = cert.SyncToHeight(15, 5)
+	assert.True(certifiers.IsPastTimeErr(err), "%+v", err)
+
+	// a properly signed header that doesn't link is rejected
+	next := keys.GenNextCheckpoint(check, nil, vals, []byte("sync"), 0, len(keys))
+	require.Nil(source.StoreSeed(certifiers.Seed{next, vals}))
+	bad := keys.GenCheckpoint(chainID, 22, nil, vals, []byte("bad"), 0, len(keys))
+	require.Nil(source.StoreSeed(certifiers.Seed{bad, vals}))
+	stats, err = cert.SyncToHeight(22, 0)
+	require.NotNil(err)
+	assert.Equal(2, stats.Headers)
+	assert.Equal(21, cert.Cert.State().Height)
+
+	// and missing headers are an error
+	_, err = cert.SyncToHeight(30, 0)
+	assert.NotNil(err)
+}
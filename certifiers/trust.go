@@ -0,0 +1,55 @@
+package certifiers
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// DefaultTrustLevel is the lowest safe trust level, as long as less
+	// than 1/3 of a validator set we trust can be byzantine
+	DefaultTrustLevel = Fraction{1, 3}
+)
+
+// Fraction is a portion of the voting power of a validator set
+type Fraction struct {
+	Numerator   int64 `json:"numerator"`
+	Denominator int64 `json:"denominator"`
+}
+
+// ParseFraction reads a fraction in the form "1/3"
+func ParseFraction(str string) (Fraction, error) {
+	var f Fraction
+	_, err := fmt.Sscanf(str, "%d/%d", &f.Numerator, &f.Denominator)
+	if err != nil {
+		return f, errors.Errorf("Cannot parse fraction '%s'", str)
+	}
+	return f, nil
+}
+
+func (f Fraction) String() string {
+	return fmt.Sprintf("%d/%d", f.Numerator, f.Denominator)
+}
+
+// IsZero is true for an unset Fraction
+func (f Fraction) IsZero() bool {
+	return f.Numerator == 0 && f.Denominator == 0
+}
+
+// Of returns this fraction of the given power (rounded down)
+func (f Fraction) Of(power int64) int64 {
+	return power * f.Numerator / f.Denominator
+}
+
+// ValidateTrustLevel makes sure this is a sensible trust level,
+// between 1/3 and 1.  Anything less than 1/3 may allow a byzantine
+// minority to fool us.
+func (f Fraction) ValidateTrustLevel() error {
+	if f.Denominator <= 0 ||
+		3*f.Numerator < f.Denominator ||
+		f.Numerator > f.Denominator {
+		return errors.Errorf("Trust level %s must be between 1/3 and 1", f)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package certifiers
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tmlibs/log"
+)
+
+// Updater keeps an InquiringCertifier up to date in the background.
+//
+// Every interval, it checks the latest seed from the SeedSource, and if
+// the validators changed, it updates to them (storing the new seeds in
+// TrustedSeeds).  This keeps bisection distances short, so requests don't
+// have to wait for a long update when they hit a new validator set.
+type Updater struct {
+	cert     *InquiringCertifier
+	interval time.Duration
+	logger   log.Logger
+
+	mtx    sync.Mutex
+	status UpdaterStatus
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+// UpdaterStatus reports the progress of an Updater
+type UpdaterStatus struct {
+	Checks    int       // number of times we checked the source
+	Updates   int       // number of times we moved to new validators
+	Height    int       // height of the last seed we saw
+	LastCheck time.Time // when we last checked
+	LastError error     // error from the last check, if any
+}
+
+// NewUpdater checks for new validators every interval, once started
+func NewUpdater(cert *InquiringCertifier, interval time.Duration) *Updater {
+	return &Updater{
+		cert:     cert,
+		interval: interval,
+		logger:   log.NewNopLogger(),
+	}
+}
+
+// SetLogger reports all progress to the logger
+func (u *Updater) SetLogger(logger log.Logger) {
+	u.logger = logger
+}
+
+// Status returns the progress so far
+func (u *Updater) Status() UpdaterStatus {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	return u.status
+}
+
+// Start runs the loop in the background, until Stop is called
+func (u *Updater) Start() {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if u.quit != nil {
+		return
+	}
+	u.quit = make(chan struct{})
+	u.done = make(chan struct{})
+	go u.loop(u.quit, u.done)
+}
+
+// Stop ends the loop and waits for it to finish
+func (u *Updater) Stop() {
+	u.mtx.Lock()
+	quit, done := u.quit, u.done
+	u.quit, u.done = nil, nil
+	u.mtx.Unlock()
+	if quit != nil {
+		close(quit)
+		<-done
+	}
+}
+
+func (u *Updater) loop(quit, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+
+	for {
+		u.Check()
+		select {
+		case <-quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check gets the latest seed from the source, and updates to it if the
+// validators changed.  The loop calls this, but you can also call it
+// directly.
+func (u *Updater) Check() error {
+	height, updated, err := u.check()
+
+	u.mtx.Lock()
+	u.status.Checks++
+	u.status.LastCheck = time.Now()
+	u.status.LastError = err
+	if err == nil {
+		u.status.Height = height
+	}
+	if updated {
+		u.status.Updates++
+	}
+	u.mtx.Unlock()
+	return err
+}
+
+func (u *Updater) check() (int, bool, error) {
+	seed, err := LatestSeed(u.cert.SeedSource)
+	if err != nil {
+		u.logger.Error("Cannot get latest seed", "err", err)
+		return 0, false, err
+	}
+
+	// nothing to do if our validators are still valid
+	if bytes.Equal(seed.Hash(), u.cert.Cert.Validators().Hash()) {
+		u.logger.Debug("Validators unchanged", "height", seed.Height())
+		return seed.Height(), false, nil
+	}
+
+	u.logger.Info("Validators changed, updating", "height", seed.Height(),
+		"hash", seed.Hash())
+	stats, err := u.cert.UpdateToHeight(seed.Height())
+	if err != nil {
+		u.logger.Error("Cannot update validators", "height", seed.Height(), "err", err)
+		return 0, false, err
+	}
+	u.logger.Info("Updated validators", "height", seed.Height(),
+		"fetched", stats.Fetched, "stored", stats.Stored)
+	return seed.Height(), true, nil
+}
@@ -0,0 +1,63 @@
+package certifiers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/light-client/certifiers"
+)
+
+func TestUpdater(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+	trust := certifiers.NewMemStoreProvider()
+	source := certifiers.NewMemStoreProvider()
+
+	chainID := "updater-test"
+	keys := certifiers.GenValKeys(5)
+	vals := keys.ToValidators(10, 0)
+	cert := certifiers.NewInquiring(chainID, vals, trust, source)
+	u := certifiers.NewUpdater(cert, 10*time.Millisecond)
+
+	// nothing in the source
+	err := u.Check()
+	assert.NotNil(err)
+
+	// same validators, nothing to do
+	cp := keys.GenCheckpoint(chainID, 10, nil, vals, []byte("same"), 0, len(keys))
+	require.Nil(source.StoreSeed(certifiers.Seed{cp, vals}))
+	err = u.Check()
+	require.Nil(err, "%+v", err)
+	status := u.Status()
+	assert.Equal(2, status.Checks)
+	assert.Equal(0, status.Updates)
+	assert.Equal(10, status.Height)
+	assert.Nil(status.LastError)
+
+	// the validators change
+	keys = keys.Extend(1)
+	vals = keys.ToValidators(10, 0)
+	cp = keys.GenCheckpoint(chainID, 20, nil, vals, []byte("new"), 0, len(keys))
+	require.Nil(source.StoreSeed(certifiers.Seed{cp, vals}))
+	err = u.Check()
+	require.Nil(err, "%+v", err)
+	assert.Equal(1, u.Status().Updates)
+	assert.Equal(vals.Hash(), cert.Cert.Validators().Hash())
+	seed, err := certifiers.LatestSeed(trust)
+	require.Nil(err, "%+v", err)
+	assert.Equal(20, seed.Height())
+
+	// and the loop picks up later changes by itself
+	keys = keys.Extend(1)
+	vals = keys.ToValidators(10, 0)
+	cp = keys.GenCheckpoint(chainID, 30, nil, vals, []byte("newer"), 0, len(keys))
+	require.Nil(source.StoreSeed(certifiers.Seed{cp, vals}))
+	u.Start()
+	for i := 0; i < 100 && u.Status().Updates < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	u.Stop()
+	assert.Equal(2, u.Status().Updates)
+	assert.Equal(30, cert.Cert.State().Height)
+}
@@ -0,0 +1,166 @@
+package certifiers
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+
+	crypto "github.com/tendermint/go-crypto"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/tendermint/types"
+)
+
+// SignatureWorkers is the number of goroutines used to verify the
+// signatures of one commit.  Set it to 1 to verify them one by one.
+//
+// We don't batch verify ed25519 signatures, as go-crypto has no batch
+// api yet, so every signature is checked on its own.
+var SignatureWorkers = runtime.NumCPU()
+
+// VerifyCommitParallel does the same checks as ValidatorSet.VerifyCommit,
+// but checks the signatures on SignatureWorkers goroutines, biggest
+// validators first, and stops as soon as over 2/3 of the power signed.
+//
+// Unlike VerifyCommit, an invalid signature is not an error, it just
+// doesn't count.  Which signatures we check before we stop depends on
+// timing, so this is the only rule that gives the same answer every
+// time.  Don't use it to look for evidence.
+func VerifyCommitParallel(vals *types.ValidatorSet, chainID string,
+	blockID types.BlockID, height int, commit *types.Commit) error {
+
+	err := checkPrecommits(vals, height, commit)
+	if err != nil {
+		return err
+	}
+
+	checks := []sigCheck{}
+	for idx, precommit := range commit.Precommits {
+		if precommit == nil || !blockID.Equals(precommit.BlockID) {
+			continue // Not an error, but doesn't count
+		}
+		_, val := vals.GetByIndex(idx)
+		checks = append(checks, sigCheck{val, val, precommit})
+	}
+
+	tallied := int64(0)
+	needed := vals.TotalVotingPower() * 2 / 3
+	ok := verifySignatures(chainID, checks, func(c sigCheck) bool {
+		tallied += c.val.VotingPower
+		return tallied > needed
+	})
+	if ok {
+		return nil
+	}
+	return fmt.Errorf("Invalid commit -- insufficient voting power: got %v, needed %v",
+		tallied, needed+1)
+}
+
+// checkPrecommits makes sure the commit matches the validators, height,
+// and round, without checking any signatures
+func checkPrecommits(vals *types.ValidatorSet, height int, commit *types.Commit) error {
+	if vals.Size() != len(commit.Precommits) {
+		return fmt.Errorf("Invalid commit -- wrong set size: %v vs %v", vals.Size(), len(commit.Precommits))
+	}
+	if height != commit.Height() {
+		return fmt.Errorf("Invalid commit -- wrong height: %v vs %v", height, commit.Height())
+	}
+
+	round := commit.Round()
+	for idx, precommit := range commit.Precommits {
+		if precommit == nil {
+			continue
+		}
+		if precommit.Height != height {
+			return lc.ErrHeightMismatch(height, precommit.Height)
+		}
+		if precommit.Round != round {
+			return fmt.Errorf("Invalid commit -- wrong round: %v vs %v", round, precommit.Round)
+		}
+		if precommit.Type != types.VoteTypePrecommit {
+			return fmt.Errorf("Invalid commit -- not precommit @ index %v", idx)
+		}
+	}
+	return nil
+}
+
+// sigCheck is one signature to verify.  val signed the vote (and is used
+// to weigh it), cur is the same validator in another set, if any.
+type sigCheck struct {
+	val  *types.Validator
+	cur  *types.Validator
+	vote *types.Vote
+}
+
+func (c sigCheck) verify(chainID string) bool {
+	return verifyVote(c.val.PubKey, chainID, c.vote)
+}
+
+func verifyVote(key crypto.PubKey, chainID string, vote *types.Vote) bool {
+	return key.VerifyBytes(types.SignBytes(chainID, vote), vote.Signature)
+}
+
+type sigResult struct {
+	check sigCheck
+	valid bool
+}
+
+// verifySignatures verifies the checks on SignatureWorkers goroutines,
+// biggest validators first, and calls enough for every valid signature
+// in the order they finish.  Invalid signatures are skipped.  It stops
+// and returns true as soon as enough returns true.
+func verifySignatures(chainID string, checks []sigCheck, enough func(sigCheck) bool) bool {
+	sort.Sort(byPower(checks))
+
+	// no need for goroutines if we only have one worker
+	workers := SignatureWorkers
+	if workers > len(checks) {
+		workers = len(checks)
+	}
+	if workers <= 1 {
+		for _, c := range checks {
+			if c.verify(chainID) && enough(c) {
+				return true
+			}
+		}
+		return false
+	}
+
+	jobs := make(chan sigCheck, len(checks))
+	for _, c := range checks {
+		jobs <- c
+	}
+	close(jobs)
+
+	// buffered, so no worker ever blocks if we stop early
+	results := make(chan sigResult, len(checks))
+	done := make(chan struct{})
+	defer close(done)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for c := range jobs {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				results <- sigResult{c, c.verify(chainID)}
+			}
+		}()
+	}
+
+	for range checks {
+		r := <-results
+		if r.valid && enough(r.check) {
+			return true
+		}
+	}
+	return false
+}
+
+type byPower []sigCheck
+
+func (b byPower) Len() int      { return len(b) }
+func (b byPower) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byPower) Less(i, j int) bool {
+	return b[i].val.VotingPower > b[j].val.VotingPower
+}
@@ -0,0 +1,60 @@
+package certifiers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/light-client/certifiers"
+)
+
+func TestVerifyCommitParallel(t *testing.T) {
+	assert := assert.New(t)
+
+	chainID := "verify-parallel"
+	keys := certifiers.GenValKeys(8)
+	vals := keys.ToValidators(10, 2)
+
+	defer func(w int) { certifiers.SignatureWorkers = w }(certifiers.SignatureWorkers)
+
+	cases := []struct {
+		first, last int
+		corrupt     int // how many signatures to break
+		valid       bool
+	}{
+		{0, 8, 0, true},
+		{2, 8, 0, true},
+		{0, 4, 0, false},
+		{5, 8, 0, false},
+		// an invalid signature doesn't count, but isn't an error either
+		{0, 8, 1, true},
+		{2, 8, 1, false},
+		// more than 1/3 invalid can never pass
+		{0, 8, 3, false},
+	}
+
+	for _, workers := range []int{1, 4, 8} {
+		certifiers.SignatureWorkers = workers
+		for i, tc := range cases {
+			check := keys.GenCheckpoint(chainID, 10+i, nil, vals, []byte("par"), tc.first, tc.last)
+			// break the votes of the biggest validators (the last keys)
+			for j := 0; j < tc.corrupt; j++ {
+				addr := keys[len(keys)-1-j].PubKey().Address()
+				idx, _ := vals.GetByAddress(addr)
+				vote := check.Commit.Precommits[idx]
+				vote.Signature = keys[0].Sign([]byte("junk"))
+			}
+
+			err := certifiers.VerifyCommitParallel(vals, chainID,
+				check.Commit.BlockID, check.Height(), check.Commit)
+			if tc.valid {
+				assert.Nil(err, "%d/%d: %+v", workers, i, err)
+			} else {
+				assert.NotNil(err, "%d/%d", workers, i)
+			}
+
+			// and the certifier agrees
+			err = certifiers.NewStatic(chainID, vals).Certify(check)
+			assert.Equal(tc.valid, err == nil, "%d/%d: %+v", workers, i, err)
+		}
+	}
+}
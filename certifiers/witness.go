@@ -0,0 +1,129 @@
+package certifiers
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/tendermint/go-wire/data"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/tendermint/types"
+)
+
+// ForkEvidence holds two conflicting headers at the same height,
+// that were both properly signed.  This means the chain forked, or some
+// validators are signing a conflicting history.
+type ForkEvidence struct {
+	Primary lc.Checkpoint `json:"primary"`
+	Witness lc.Checkpoint `json:"witness"`
+	// Signers are the addresses of the validators who signed both
+	Signers []data.Bytes `json:"signers"`
+}
+
+//--------------------------------------------
+
+type errForkDetected struct {
+	evidence *ForkEvidence
+}
+
+func (e errForkDetected) Error() string {
+	return fmt.Sprintf("Conflicting headers at height %d: %X vs %X",
+		e.evidence.Primary.Height(),
+		e.evidence.Primary.Header.Hash(),
+		e.evidence.Witness.Header.Hash())
+}
+
+// IsForkDetectedErr checks whether an error is due to conflicting headers
+func IsForkDetectedErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := errors.Cause(err).(errForkDetected)
+	return ok
+}
+
+// GetForkEvidence returns the evidence attached to a fork error, or nil
+func GetForkEvidence(err error) *ForkEvidence {
+	if err == nil {
+		return nil
+	}
+	if e, ok := errors.Cause(err).(errForkDetected); ok {
+		return e.evidence
+	}
+	return nil
+}
+
+func ErrForkDetected(evidence *ForkEvidence) error {
+	return errors.WithStack(errForkDetected{evidence})
+}
+
+//--------------------------------------------
+
+// crossCheck asks every witness for the header at the height of this
+// checkpoint, which we already certified.  If any witness can show us a
+// different, validly signed header, we record the evidence and stop
+// certifying.
+//
+// Witnesses that are unavailable, or return junk, are ignored.
+func (c *InquiringCertifier) crossCheck(check lc.Checkpoint) error {
+	for _, w := range c.Witnesses {
+		seed, err := w.GetByHeight(check.Height())
+		if err != nil || seed.Height() != check.Height() {
+			continue
+		}
+		if bytes.Equal(seed.Header.Hash(), check.Header.Hash()) {
+			continue
+		}
+		if !c.validlySigned(seed) {
+			continue
+		}
+
+		// we have two signed headers, this is bad...
+		fork := &ForkEvidence{
+			Primary: check,
+			Witness: seed.Checkpoint,
+			Signers: CommonSigners(check.Commit, seed.Commit),
+		}
+		c.mtx.Lock()
+		c.fork = fork
+		c.mtx.Unlock()
+		return ErrForkDetected(fork)
+	}
+	return nil
+}
+
+// validlySigned returns true if this seed is a properly signed header
+// from our chain, that our validators would accept.  Anyone can make up
+// their own validators, so that alone proves nothing.
+func (c *InquiringCertifier) validlySigned(seed Seed) bool {
+	if seed.Validators == nil {
+		return false
+	}
+	if seed.ValidateBasic(c.ChainID()) != nil {
+		return false
+	}
+	if !bytes.Equal(seed.Validators.Hash(), seed.Header.ValidatorsHash) {
+		return false
+	}
+	return c.Cert.checkChange(seed.Checkpoint, seed.Validators) == nil
+}
+
+// CommonSigners returns the addresses of all validators that
+// signed both commits.
+func CommonSigners(a, b *types.Commit) []data.Bytes {
+	signed := map[string]bool{}
+	for _, vote := range a.Precommits {
+		if vote != nil && a.BlockID.Equals(vote.BlockID) {
+			signed[string(vote.ValidatorAddress)] = true
+		}
+	}
+
+	res := []data.Bytes{}
+	for _, vote := range b.Precommits {
+		if vote != nil && b.BlockID.Equals(vote.BlockID) &&
+			signed[string(vote.ValidatorAddress)] {
+			res = append(res, vote.ValidatorAddress)
+		}
+	}
+	return res
+}
@@ -0,0 +1,66 @@
+package certifiers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/light-client/certifiers"
+)
+
+func TestWitnessDetectsFork(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	chainID := "witness-fork"
+	keys := certifiers.GenValKeys(4)
+	vals := keys.ToValidators(10, 0)
+
+	honest := certifiers.NewMemStoreProvider()
+	liar := certifiers.NewMemStoreProvider()
+	forked := certifiers.NewMemStoreProvider()
+
+	cert := certifiers.NewInquiring(chainID, vals,
+		certifiers.NewMemStoreProvider(), certifiers.NewMissingProvider())
+	cert.Witnesses = []certifiers.Provider{
+		certifiers.NewMissingProvider(), honest, liar, forked,
+	}
+
+	// the honest witness has the same header
+	h := 10
+	check := keys.GenCheckpoint(chainID, h, nil, vals, []byte("good"), 0, len(keys))
+	require.Nil(honest.StoreSeed(certifiers.Seed{check, vals}))
+
+	// the liar has a different header, signed by validators we don't know
+	fakes := certifiers.GenValKeys(4)
+	fakeVals := fakes.ToValidators(10, 0)
+	fake := fakes.GenCheckpoint(chainID, h, nil, fakeVals, []byte("fake"), 0, len(fakes))
+	require.Nil(liar.StoreSeed(certifiers.Seed{fake, fakeVals}))
+
+	// nothing to complain about so far (the liar proves nothing)
+	err := cert.Certify(check)
+	require.Nil(err, "%+v", err)
+	assert.Nil(cert.Fork())
+
+	// but now our validators sign two different headers at one height
+	h = 20
+	check = keys.GenCheckpoint(chainID, h, nil, vals, []byte("good"), 0, len(keys))
+	other := keys.GenCheckpoint(chainID, h, nil, vals, []byte("evil"), 1, len(keys))
+	require.Nil(forked.StoreSeed(certifiers.Seed{other, vals}))
+
+	err = cert.Certify(check)
+	require.NotNil(err)
+	assert.True(certifiers.IsForkDetectedErr(err), "%+v", err)
+	ev := certifiers.GetForkEvidence(err)
+	if assert.NotNil(ev) {
+		assert.Equal(check, ev.Primary)
+		assert.Equal(other, ev.Witness)
+		// everyone but the first signed both
+		assert.Equal(len(keys)-1, len(ev.Signers))
+	}
+	assert.Equal(ev, cert.Fork())
+
+	// and we refuse to certify anything after that
+	check = keys.GenCheckpoint(chainID, h+1, nil, vals, []byte("good"), 0, len(keys))
+	err = cert.Certify(check)
+	assert.True(certifiers.IsForkDetectedErr(err), "%+v", err)
+}
@@ -29,10 +29,10 @@ FIRST: test that current basecoin-proxy command works against v0.9/v0.4 release
   * TODO: list????
 * proofs
   * get - display just as binary or accept plug in to display as json?
-  * list????
-  * show?
-  * export
-  * import (--dry-run)
+  * list - lists all proofs we verified and stored
+  * show - shows one stored proof, after validating it again
+  * export - writes a proof with everything needed to verify it offline
+  * import (--dry-run) - verifies such a proof against our trusted seeds
 
 tmcli proof state get --app=<app> --key=<key> --height=<h>
 
@@ -98,7 +98,14 @@ func init() {
 	pr := proofs.RootCmd
 	// these are default parsers, but you optional in your app
 	pr.AddCommand(proofs.TxCmd)
+	pr.AddCommand(proofs.TxsCmd)
+	pr.AddCommand(proofs.ExportCmd)
+	pr.AddCommand(proofs.ImportCmd)
+	pr.AddCommand(proofs.ListCmd)
+	pr.AddCommand(proofs.ShowCmd)
 	pr.AddCommand(proofs.KeyCmd)
+	pr.AddCommand(proofs.KeysCmd)
+	pr.AddCommand(proofs.RangeCmd)
 
 	// here is how you would add the custom txs... but don't really add demo in your app
 	tr := txs.RootCmd
@@ -4,8 +4,10 @@ Package commands contains any general setup/helpers valid for all subcommands
 package commands
 
 import (
-	"errors"
+	"encoding/hex"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
@@ -13,6 +15,7 @@ import (
 
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 
+	lc "github.com/tendermint/light-client"
 	"github.com/tendermint/light-client/certifiers"
 	"github.com/tendermint/light-client/certifiers/client"
 	"github.com/tendermint/light-client/certifiers/files"
@@ -21,16 +24,31 @@ import (
 var (
 	trustedProv certifiers.Provider
 	sourceProv  certifiers.Provider
+	checkCache  *certifiers.CheckpointCache
 )
 
 const (
-	ChainFlag = "chain-id"
-	NodeFlag  = "node"
+	ChainFlag   = "chain-id"
+	NodeFlag    = "node"
+	TrustFlag   = "trust-level"
+	PeriodFlag  = "trusting-period"
+	WitnessFlag = "witness"
 )
 
+// MemCacheSize is the number of seeds we keep in memory in front of
+// the files store
+var MemCacheSize = 1000
+
+// DefaultTrustingPeriod should be a bit shorter than the unbonding period
+// of the chain, so no validators we trust can have unbonded yet
+var DefaultTrustingPeriod = 21 * 24 * time.Hour
+
 func AddBasicFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().String(ChainFlag, "", "Chain ID of tendermint node")
 	cmd.PersistentFlags().String(NodeFlag, "", "<host>:<port> to tendermint rpc interface for this chain")
+	cmd.PersistentFlags().String(TrustFlag, "", "Portion of known validators that must sign a new validator set, like 1/3 (default requires 2/3 of both sets)")
+	cmd.PersistentFlags().Duration(PeriodFlag, DefaultTrustingPeriod, "Reject seeds older than this, should be less than the unbonding period (0 to disable)")
+	cmd.PersistentFlags().StringSlice(WitnessFlag, nil, "<host>:<port> of other nodes to cross-check all headers against, to detect forks")
 }
 
 func GetChainID() string {
@@ -46,7 +64,7 @@ func GetProviders() (trusted certifiers.Provider, source certifiers.Provider) {
 		// initialize provider with files stored in homedir
 		rootDir := viper.GetString(cli.HomeFlag)
 		trustedProv = certifiers.NewCacheProvider(
-			certifiers.NewMemStoreProvider(),
+			certifiers.NewBoundedMemStoreProvider(MemCacheSize),
 			files.NewProvider(rootDir),
 		)
 		node := viper.GetString(NodeFlag)
@@ -55,6 +73,41 @@ func GetProviders() (trusted certifiers.Provider, source certifiers.Provider) {
 	return trustedProv, sourceProv
 }
 
+// GetCheckpointCache returns the cache of certified headers, shared by
+// all commands in this process
+func GetCheckpointCache() *certifiers.CheckpointCache {
+	if checkCache == nil {
+		checkCache = certifiers.NewCheckpointCache(certifiers.DefaultCheckpointCacheSize)
+	}
+	return checkCache
+}
+
+// GetSecureNode wraps GetNode, so everything it returns is verified
+// by our certifier
+func GetSecureNode() (rpcclient.Client, error) {
+	cert, err := GetCertifier()
+	if err != nil {
+		return nil, err
+	}
+	genesis, err := GetTrustedGenesis()
+	if err != nil {
+		return nil, err
+	}
+	return client.Wrap(GetNode(), cert).WithGenesis(genesis), nil
+}
+
+// GetTrustedGenesis returns what we recorded about the genesis on init
+func GetTrustedGenesis() (lc.TrustedGenesis, error) {
+	res := lc.TrustedGenesis{ChainID: GetChainID()}
+	var err error
+	res.Hash, err = hex.DecodeString(viper.GetString(GenesisHashKey))
+	if err != nil {
+		return res, errors.WithStack(err)
+	}
+	res.ValidatorHash, err = hex.DecodeString(viper.GetString(GenesisValsKey))
+	return res, errors.WithStack(err)
+}
+
 func GetCertifier() (*certifiers.InquiringCertifier, error) {
 	// load up the latest store....
 	trust, source := GetProviders()
@@ -69,5 +122,42 @@ func GetCertifier() (*certifiers.InquiringCertifier, error) {
 	}
 	cert := certifiers.NewInquiring(
 		viper.GetString(ChainFlag), seed.Validators, trust, source)
+
+	// pick up where we left off last time, so we never go back in time
+	cert.Cert.LastHeight = seed.Height()
+	cert.Cert.LastHash = seed.Header.Hash()
+	cert.Cert.LastTime = seed.Header.Time
+	cert.Cert.UpdateHeight = seed.Height()
+	store := files.NewStateStore(viper.GetString(cli.HomeFlag))
+	state, err := store.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	cert.Cert.Restore(state)
+	cert.Cert.Store = store
+
+	// an old seed may be signed by validators that already unbonded
+	cert.SetTrustingPeriod(viper.GetDuration(PeriodFlag), nil)
+	if cert.Cert.Expired() {
+		return nil, errors.Wrap(certifiers.ErrSeedExpired(),
+			"Please run init --force-reset with a recent seed from a trusted source")
+	}
+
+	// cross-check with other nodes if desired
+	for _, w := range viper.GetStringSlice(WitnessFlag) {
+		cert.Witnesses = append(cert.Witnesses, client.NewHTTP(w))
+	}
+
+	// optionally allow bigger jumps when updating validators
+	if level := viper.GetString(TrustFlag); level != "" {
+		f, err := certifiers.ParseFraction(level)
+		if err != nil {
+			return nil, err
+		}
+		err = cert.SetTrustLevel(f)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return cert, nil
 }
@@ -20,6 +20,7 @@ import (
 
 	"github.com/tendermint/tendermint/types"
 
+	lc "github.com/tendermint/light-client"
 	"github.com/tendermint/light-client/certifiers"
 	"github.com/tendermint/light-client/certifiers/files"
 )
@@ -33,6 +34,11 @@ const (
 	HashFlag    = "valhash"
 	GenesisFlag = "genesis"
 
+	// GenesisHashKey and GenesisValsKey are where we record the genesis
+	// in the config file
+	GenesisHashKey = "genesis-hash"
+	GenesisValsKey = "genesis-valhash"
+
 	ConfigFile = "config.toml"
 )
 
@@ -53,7 +59,7 @@ func init() {
 	InitCmd.Flags().Bool("force-reset", false, "Wipe clean an existing client store, except for keys")
 	InitCmd.Flags().String(SeedFlag, "", "Seed file to import (optional)")
 	InitCmd.Flags().String(HashFlag, "", "Trusted validator hash (must match to accept)")
-	InitCmd.Flags().String(GenesisFlag, "", "Genesis file with chainid and validators (optional)")
+	InitCmd.Flags().String(GenesisFlag, "", "Genesis file with chainid and validators (optional, needed to verify rpc genesis)")
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
@@ -181,6 +187,8 @@ func WasInited(root string) (bool, error) {
 func checkGenesis(cmd *cobra.Command) error {
 	genesis := viper.GetString(GenesisFlag)
 	if genesis == "" {
+		// we have nothing to check the genesis of the node against
+		fmt.Fprintln(os.Stderr, "No --genesis file given, so rpc genesis will only verify the chain id")
 		return nil
 	}
 
@@ -195,6 +203,17 @@ func checkGenesis(cmd *cobra.Command) error {
 	hexHash := hex.EncodeToString(hash)
 	flags.Set(HashFlag, hexHash)
 
+	return recordGenesis(doc)
+}
+
+// recordGenesis sets the genesis hashes, to be stored in the config file
+func recordGenesis(doc *types.GenesisDoc) error {
+	trusted, err := lc.NewTrustedGenesis(doc)
+	if err != nil {
+		return err
+	}
+	viper.Set(GenesisHashKey, hex.EncodeToString(trusted.Hash))
+	viper.Set(GenesisValsKey, hex.EncodeToString(trusted.ValidatorHash))
 	return nil
 }
 
@@ -226,10 +245,12 @@ func isEmpty(dir string) (bool, error) {
 }
 
 type Config struct {
-	Chain    string `toml:"chain-id,omitempty"`
-	Node     string `toml:"node,omitempty"`
-	Output   string `toml:"output,omitempty"`
-	Encoding string `toml:"encoding,omitempty"`
+	Chain         string `toml:"chain-id,omitempty"`
+	Node          string `toml:"node,omitempty"`
+	Output        string `toml:"output,omitempty"`
+	Encoding      string `toml:"encoding,omitempty"`
+	GenesisHash   string `toml:"genesis-hash,omitempty"`
+	GenesisValSet string `toml:"genesis-valhash,omitempty"`
 }
 
 func setConfig(flags *pflag.FlagSet, f string, v *string) {
@@ -253,6 +274,8 @@ func initConfigFile(cmd *cobra.Command) error {
 	setConfig(flags, NodeFlag, &cfg.Node)
 	setConfig(flags, cli.OutputFlag, &cfg.Output)
 	setConfig(flags, cli.EncodingFlag, &cfg.Encoding)
+	cfg.GenesisHash = viper.GetString(GenesisHashKey)
+	cfg.GenesisValSet = viper.GetString(GenesisValsKey)
 
 	out, err := os.Create(filepath.Join(viper.GetString(cli.HomeFlag), ConfigFile))
 	if err != nil {
@@ -0,0 +1,205 @@
+package proofs
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/tendermint/go-wire/data"
+	"github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tendermint/types"
+
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/light-client/certifiers"
+	"github.com/tendermint/light-client/commands"
+	"github.com/tendermint/light-client/proofs"
+)
+
+const (
+	typeFlag = "type"
+	keyFlag  = "key"
+	rootFlag = "root"
+	dryFlag  = "dry-run"
+)
+
+// ProofTypes are the types of proofs we can export, import and store,
+// register app-specific provers here
+var ProofTypes = map[string]func(client.Client) lc.Prover{
+	"key": func(node client.Client) lc.Prover { return proofs.NewAppProver(node) },
+	"tx":  func(node client.Client) lc.Prover { return proofs.NewTxProver(node) },
+}
+
+var ExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export a proof that can be verified offline",
+	Long: `This gets and certifies a proof, and writes it to a file along with the
+header it validates against, and all validator changes since a trusted root.
+
+Anyone who trusts the root seed can verify it without talking to a node.
+The root defaults to the oldest seed we trust.`,
+	RunE:         commands.RequireInit(exportProof),
+	SilenceUsage: true,
+}
+
+var ImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Verify a proof exported by another client",
+	Long: `This verifies a proof bundle offline, against the seeds we trust.
+Unless --dry-run, we also store the validator changes in the bundle.`,
+	RunE:         commands.RequireInit(importProof),
+	SilenceUsage: true,
+}
+
+func init() {
+	ExportCmd.Flags().String(typeFlag, "key", "Type of proof (key, tx)")
+	ExportCmd.Flags().String(keyFlag, "", "Key of the proof, as hex (the hash for txs)")
+	ExportCmd.Flags().Int(heightFlag, 0, "Height to query (skip to use latest block)")
+	ExportCmd.Flags().String(rootFlag, "", "Validator hash of the root seed")
+	ImportCmd.Flags().Bool(dryFlag, false, "Verify the proof, but store nothing")
+}
+
+func getBundleProver(proofType string, node client.Client) (lc.Prover, error) {
+	mk, ok := ProofTypes[proofType]
+	if !ok {
+		return nil, errors.Errorf("Unknown proof type %s", proofType)
+	}
+	return mk(node), nil
+}
+
+func exportProof(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 || len(args[0]) == 0 {
+		return errors.New("You must provide a filepath to output")
+	}
+	path := args[0]
+	key, err := proofs.ParseHexKey(viper.GetString(keyFlag))
+	if err != nil {
+		return err
+	}
+	if len(key) == 0 {
+		return errors.Errorf("--%s is required", keyFlag)
+	}
+
+	// get the proof, and the checkpoint it validates against
+	node := commands.GetNode()
+	proofType := viper.GetString(typeFlag)
+	prover, err := getBundleProver(proofType, node)
+	if err != nil {
+		return err
+	}
+	proof, err := prover.Get(key, uint64(GetHeight()))
+	if err != nil {
+		return err
+	}
+	h := int(proof.BlockHeight())
+	check, err := getCertifiedCheckpoint(node, h)
+	if err != nil {
+		return err
+	}
+	err = proof.Validate(check)
+	if err != nil {
+		return err
+	}
+
+	trust, _ := commands.GetProviders()
+	vals, err := getValidators(trust, node, check)
+	if err != nil {
+		return err
+	}
+	root, err := getRoot(trust, viper.GetString(rootFlag))
+	if err != nil {
+		return err
+	}
+
+	seed := certifiers.Seed{Checkpoint: check, Validators: vals}
+	bundle, err := certifiers.NewProofBundle(trust, root, seed, proofType, proof)
+	if err != nil {
+		return err
+	}
+	// make sure anyone with the root can verify this
+	_, err = bundle.Verify(trust, commands.GetChainID(), prover)
+	if err != nil {
+		return err
+	}
+	return bundle.Write(path)
+}
+
+// getValidators returns the validators that signed this checkpoint,
+// from our seeds if possible
+func getValidators(trust certifiers.Provider, node client.Client,
+	check lc.Checkpoint) (*types.ValidatorSet, error) {
+
+	vhash := check.Header.ValidatorsHash
+	seed, err := trust.GetByHash(vhash)
+	if err == nil {
+		return seed.Validators, nil
+	}
+
+	h := check.Height()
+	res, err := node.Validators(&h)
+	if err != nil {
+		return nil, err
+	}
+	vals := types.NewValidatorSet(res.Validators)
+	if !bytes.Equal(vals.Hash(), vhash) {
+		return nil, errors.Errorf("Validators %X don't match header %X",
+			vals.Hash(), vhash)
+	}
+	return vals, nil
+}
+
+// getRoot returns the seed with this validator hash, or our oldest seed
+func getRoot(trust certifiers.Provider, hash string) (certifiers.Seed, error) {
+	if hash != "" {
+		vhash, err := proofs.ParseHexKey(hash)
+		if err != nil {
+			return certifiers.Seed{}, err
+		}
+		return trust.GetByHash(vhash)
+	}
+
+	pruner, ok := trust.(certifiers.Pruner)
+	if !ok {
+		return certifiers.Seed{}, errors.Errorf("Please set --%s", rootFlag)
+	}
+	seeds, err := pruner.AllSeeds()
+	if err != nil {
+		return certifiers.Seed{}, err
+	}
+	if len(seeds) == 0 {
+		return certifiers.Seed{}, certifiers.ErrSeedNotFound()
+	}
+	return seeds[0], nil
+}
+
+func importProof(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 || len(args[0]) == 0 {
+		return errors.New("You must provide an input file")
+	}
+	bundle, err := certifiers.LoadProofBundle(args[0])
+	if err != nil {
+		return err
+	}
+
+	// we only need the prover to read the proof, never to query
+	prover, err := getBundleProver(bundle.ProofType, nil)
+	if err != nil {
+		return err
+	}
+	trust, _ := commands.GetProviders()
+	proof, err := bundle.Verify(trust, commands.GetChainID(), prover)
+	if err != nil {
+		return err
+	}
+
+	if !viper.GetBool(dryFlag) {
+		for _, seed := range bundle.Seeds() {
+			err = trust.StoreSeed(seed)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return OutputProof(data.Bytes(proof.Data()), proof.BlockHeight())
+}
@@ -29,7 +29,7 @@ func GetAndParseAppProof(key []byte, data interface{}) (lc.Proof, error) {
 	node := commands.GetNode()
 	prover := proofs.NewAppProver(node)
 
-	proof, err := GetProof(node, prover, key, height)
+	proof, err := GetAndStoreProof(node, prover, "key", key, height)
 	if err != nil {
 		return proof, err
 	}
@@ -40,26 +40,79 @@ func GetAndParseAppProof(key []byte, data interface{}) (lc.Proof, error) {
 
 // GetProof performs the get command directly from the proof (not from the CLI)
 func GetProof(node client.Client, prover lc.Prover, key []byte, height int) (proof lc.Proof, err error) {
-	proof, err = prover.Get(key, uint64(height))
+	proof, _, err = getProof(node, prover, key, height)
+	return proof, err
+}
+
+// getProof also returns the certified checkpoint the proof validates against
+func getProof(node client.Client, prover lc.Prover, key []byte, height int) (lc.Proof, lc.Checkpoint, error) {
+	proof, err := prover.Get(key, uint64(height))
+	if err != nil {
+		return nil, lc.Checkpoint{}, err
+	}
+	check, err := certifyProof(node, proof)
+	return proof, check, err
+}
+
+// GetRangeProof gets all key-value pairs in [start, end) along with a proof
+// that the result is complete, and validates it against a certified header
+func GetRangeProof(node client.Client, prover lc.RangeProver, start, end []byte, height int) (proof lc.Proof, err error) {
+	proof, err = prover.GetRange(start, end, uint64(height))
 	if err != nil {
 		return
 	}
-	ph := int(proof.BlockHeight())
+	_, err = certifyProof(node, proof)
+	return proof, err
+}
+
+// GetBatchProofs gets proofs for all keys at the latest height, and
+// validates them all against a single certified header.  Keys we could
+// not prove are in the Errors of the result.
+func GetBatchProofs(node client.Client, prover proofs.AppProver, keys [][]byte) (proofs.BatchResult, error) {
+	res := prover.GetBatch(keys)
+	if len(res.Proofs) == 0 && len(res.Absent) == 0 {
+		return res, nil
+	}
+
+	check, err := getCertifiedCheckpoint(node, int(res.Height))
+	if err != nil {
+		return res, err
+	}
+	res.Validate(check)
+	return res, nil
+}
+
+// certifyProof gets and certifies the header for this proof, and makes
+// sure the proof validates against it
+func certifyProof(node client.Client, proof lc.Proof) (lc.Checkpoint, error) {
+	check, err := getCertifiedCheckpoint(node, int(proof.BlockHeight()))
+	if err != nil {
+		return check, err
+	}
+
+	// validate the proof against the certified header to ensure data integrity
+	return check, proof.Validate(check)
+}
+
+// getCertifiedCheckpoint gets a signed header at ph and certifies it
+func getCertifiedCheckpoint(node client.Client, ph int) (lc.Checkpoint, error) {
+	// maybe we already certified this header
+	cache := commands.GetCheckpointCache()
+	if check, ok := cache.Get(ph); ok {
+		return check, nil
+	}
+
 	// here is the certifier, root of all knowledge
 	cert, err := commands.GetCertifier()
 	if err != nil {
-		return
+		return lc.Checkpoint{}, err
 	}
 
 	// get and validate a signed header for this proof
-
-	// FIXME: cannot use cert.GetByHeight for now, as it also requires
-	// Validators and will fail on querying tendermint for non-current height.
-	// When this is supported, we should use it instead...
 	client.WaitForHeight(node, ph, nil)
 	commit, err := node.Commit(ph)
 	if err != nil {
-		return
+		return lc.Checkpoint{}, err
 	}
 	check := lc.Checkpoint{
 		Header: commit.Header,
@@ -67,16 +120,12 @@ func GetProof(node client.Client, prover lc.Prover, key []byte, height int) (pro
 	}
 	err = cert.Certify(check)
 	if err != nil {
-		return
+		return check, err
 	}
-
-	// validate the proof against the certified header to ensure data integrity
-	err = proof.Validate(check)
-	if err != nil {
-		return
+	if commit.CanonicalCommit {
+		cache.Add(check)
 	}
-
-	return proof, err
+	return check, nil
 }
 
 // ParseHexKey parses the key flag as hex and converts to bytes or returns error
@@ -0,0 +1,77 @@
+package proofs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tendermint/go-wire/data"
+
+	"github.com/tendermint/light-client/commands"
+	"github.com/tendermint/light-client/proofs"
+)
+
+var KeysCmd = &cobra.Command{
+	Use:   "keys [key]...",
+	Short: "Prove many keys of the abci app at one height",
+	Long: `This looks up all given keys (as hex) in the abci app at the latest
+height, and verifies all proofs against one header.  Keys that are not set
+are proven to be absent.
+
+Keys we could not prove are listed with the error.`,
+	RunE: commands.RequireInit(doKeysQuery),
+}
+
+// batchOutput shows the values of all keys we proved, indexed by hex key
+type batchOutput struct {
+	Height uint64                `json:"height"`
+	Values map[string]data.Bytes `json:"values"`
+	Absent []string              `json:"absent"`
+	Errors map[string]string     `json:"errors,omitempty"`
+}
+
+func doKeysQuery(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("Missing required argument [key]")
+	}
+	keys := make([][]byte, len(args))
+	for i, arg := range args {
+		key, err := proofs.ParseHexKey(arg)
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+	}
+
+	node := commands.GetNode()
+	prover := proofs.NewAppProver(node)
+	res, err := GetBatchProofs(node, prover, keys)
+	if err != nil {
+		return err
+	}
+
+	out := batchOutput{
+		Height: res.Height,
+		Values: map[string]data.Bytes{},
+		Absent: []string{},
+		Errors: map[string]string{},
+	}
+	for k, pr := range res.Proofs {
+		out.Values[fmt.Sprintf("%X", k)] = pr.Value
+	}
+	for k := range res.Absent {
+		out.Absent = append(out.Absent, fmt.Sprintf("%X", k))
+	}
+	sort.Strings(out.Absent)
+	for k, e := range res.Errors {
+		out.Errors[fmt.Sprintf("%X", k)] = e.Error()
+	}
+	js, err := data.ToJSON(out)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(js))
+	return nil
+}
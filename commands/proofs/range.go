@@ -0,0 +1,66 @@
+package proofs
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/tendermint/light-client/commands"
+	"github.com/tendermint/light-client/proofs"
+)
+
+const (
+	startFlag  = "start"
+	endFlag    = "end"
+	prefixFlag = "prefix"
+)
+
+var RangeCmd = &cobra.Command{
+	Use:   "range",
+	Short: "Get all key-value pairs in a range, with proof nothing is missing",
+	Long: `This looks up all keys in [start, end) in the abci app, and verifies
+the proof that the result is complete.  Use --prefix to get all keys with
+a given prefix instead.
+
+All keys are hex, and an empty end means no upper bound.`,
+	RunE: commands.RequireInit(doRangeQuery),
+}
+
+func init() {
+	RangeCmd.Flags().String(startFlag, "", "First key of the range (hex)")
+	RangeCmd.Flags().String(endFlag, "", "End of the range, not included (hex)")
+	RangeCmd.Flags().String(prefixFlag, "", "Get all keys with this prefix (hex)")
+	RangeCmd.Flags().Int(heightFlag, 0, "Height to query (skip to use latest block)")
+}
+
+func doRangeQuery(cmd *cobra.Command, args []string) error {
+	start, err := proofs.ParseHexKey(viper.GetString(startFlag))
+	if err != nil {
+		return err
+	}
+	end, err := proofs.ParseHexKey(viper.GetString(endFlag))
+	if err != nil {
+		return err
+	}
+	if prefix := viper.GetString(prefixFlag); prefix != "" {
+		if len(start) != 0 || len(end) != 0 {
+			return errors.Errorf("Cannot use --%s with --%s or --%s",
+				prefixFlag, startFlag, endFlag)
+		}
+		start, err = proofs.ParseHexKey(prefix)
+		if err != nil {
+			return err
+		}
+		end = proofs.PrefixEnd(start)
+	}
+
+	node := commands.GetNode()
+	prover := proofs.NewAppProver(node)
+	proof, err := GetRangeProof(node, prover, start, end, GetHeight())
+	if err != nil {
+		return err
+	}
+
+	pairs := proof.(proofs.AppRangeProof).Pairs()
+	return OutputProof(pairs, proof.BlockHeight())
+}
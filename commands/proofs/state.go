@@ -32,7 +32,7 @@ func doKeyQuery(cmd *cobra.Command, args []string) error {
 	// get the proof -> this will be used by all prover commands
 	node := commands.GetNode()
 	prover := proofs.NewAppProver(node)
-	proof, err := GetProof(node, prover, key, height)
+	proof, err := GetAndStoreProof(node, prover, "key", key, height)
 	if err != nil {
 		return err
 	}
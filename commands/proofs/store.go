@@ -0,0 +1,122 @@
+package proofs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/tendermint/go-wire/data"
+	"github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tmlibs/cli"
+
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/light-client/certifiers/files"
+	"github.com/tendermint/light-client/commands"
+	"github.com/tendermint/light-client/proofs"
+)
+
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all proofs we verified and stored",
+	RunE:  commands.RequireInit(listProofs),
+}
+
+var ShowCmd = &cobra.Command{
+	Use:   "show <type> <key>",
+	Short: "Show a stored proof, after validating it again",
+	Long: `This loads a proof we stored earlier, with the closest height to
+--height (or the latest one), and makes sure we still trust it.
+
+The key is hex, like for the queries.`,
+	RunE: commands.RequireInit(showProof),
+}
+
+func init() {
+	ShowCmd.Flags().Int(heightFlag, 0, "Show the proof with closest height to this")
+}
+
+// GetProofStore returns where we keep all proofs we verified
+func GetProofStore() (files.ProofStore, error) {
+	trust, _ := commands.GetProviders()
+	provers := map[string]lc.Prover{}
+	for name, mk := range ProofTypes {
+		provers[name] = mk(nil)
+	}
+	dir := filepath.Join(viper.GetString(cli.HomeFlag), files.ProofDir)
+	return files.NewProofStore(dir, commands.GetChainID(), trust, provers)
+}
+
+// GetAndStoreProof does GetProof, and keeps the proof in the ProofStore,
+// so we have a record of everything we verified.
+//
+// The proof is valid even if we cannot store it, so we only warn about
+// that on stderr.
+func GetAndStoreProof(node client.Client, prover lc.Prover, proofType string,
+	key []byte, height int) (lc.Proof, error) {
+
+	proof, check, err := getProof(node, prover, key, height)
+	if err != nil {
+		return proof, err
+	}
+	store, err := GetProofStore()
+	if err == nil {
+		err = store.Put(proofType, key, proof, check)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot store proof: %v\n", err)
+	}
+	return proof, nil
+}
+
+func listProofs(cmd *cobra.Command, args []string) error {
+	store, err := GetProofStore()
+	if err != nil {
+		return err
+	}
+	infos, err := store.List()
+	if err != nil {
+		return err
+	}
+	js, err := data.ToJSON(infos)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(js))
+	return nil
+}
+
+func showProof(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return errors.New("You must provide the type and key of the proof")
+	}
+	proofType := args[0]
+	if _, ok := ProofTypes[proofType]; !ok {
+		return errors.Errorf("Unknown proof type %s", proofType)
+	}
+	key, err := proofs.ParseHexKey(args[1])
+	if err != nil {
+		return err
+	}
+
+	store, err := GetProofStore()
+	if err != nil {
+		return err
+	}
+	proof, err := store.Get(proofType, key, GetHeight())
+	if err != nil {
+		return err
+	}
+
+	var info interface{} = data.Bytes(proof.Data())
+	if proofType == "tx" {
+		info, err = TxPresenters.BruteForce(proof.Data())
+		if err != nil {
+			return err
+		}
+	}
+	return OutputProof(info, proof.BlockHeight())
+}
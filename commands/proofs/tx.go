@@ -32,7 +32,7 @@ func doTxQuery(cmd *cobra.Command, args []string) error {
 	// get the proof -> this will be used by all prover commands
 	node := commands.GetNode()
 	prover := proofs.NewTxProver(node)
-	proof, err := GetProof(node, prover, bkey, height)
+	proof, err := GetAndStoreProof(node, prover, "tx", bkey, height)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,69 @@
+package proofs
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/tendermint/go-wire/data"
+
+	"github.com/tendermint/light-client/commands"
+	"github.com/tendermint/light-client/proofs"
+)
+
+// TxTaggers are used to search txs by tag, register your app here.
+// By default, we can only search for the "hash" of a tx.
+var TxTaggers = proofs.NewTaggers()
+
+const queryFlag = "query"
+
+var TxsCmd = &cobra.Command{
+	Use:   "txs",
+	Short: "Find and prove all txs matching a query",
+	Long: `This goes through all blocks in a height range, and returns every
+tx that matches the query, along with a proof that it is in the block.
+
+Queries look like "height>=100 AND height<200 AND sender=ABCD".  Besides
+height, you can always search for the hash of a tx (as upper case hex).
+Other tags need an app-specific tagger to be registered.
+`,
+	RunE: commands.RequireInit(doTxsQuery),
+}
+
+func init() {
+	TxTaggers.Register("hash", proofs.HashTagger{})
+	TxsCmd.Flags().String(queryFlag, "", "Query for the txs to prove")
+}
+
+func doTxsQuery(cmd *cobra.Command, args []string) error {
+	q, err := proofs.ParseTxQuery(viper.GetString(queryFlag))
+	if err != nil {
+		return err
+	}
+
+	// the searcher checks every block against a certified commit
+	node, err := commands.GetSecureNode()
+	if err != nil {
+		return err
+	}
+	found, err := proofs.NewTxSearcher(node, TxTaggers).Search(q)
+	if err != nil {
+		return err
+	}
+
+	res := make([]proof, len(found))
+	for i, p := range found {
+		info, err := TxPresenters.BruteForce(p.Data())
+		if err != nil {
+			return err
+		}
+		res[i] = proof{p.BlockHeight(), info}
+	}
+	js, err := data.ToJSON(res)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(js))
+	return nil
+}
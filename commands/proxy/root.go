@@ -3,6 +3,7 @@ package proxy
 import (
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -14,6 +15,7 @@ import (
 	"github.com/tendermint/tendermint/rpc/core"
 	rpc "github.com/tendermint/tendermint/rpc/lib/server"
 
+	"github.com/tendermint/light-client/certifiers"
 	certclient "github.com/tendermint/light-client/certifiers/client"
 	"github.com/tendermint/light-client/commands"
 )
@@ -33,12 +35,14 @@ just with added trust and running locally.`,
 }
 
 const (
-	bindFlag   = "serve"
-	wsEndpoint = "/websocket"
+	bindFlag     = "serve"
+	intervalFlag = "update-interval"
+	wsEndpoint   = "/websocket"
 )
 
 func init() {
 	RootCmd.Flags().String(bindFlag, ":8888", "Serve the proxy on the given port")
+	RootCmd.Flags().Duration(intervalFlag, time.Minute, "Check for new validators this often in the background (0 to disable)")
 }
 
 // TODO: pass in a proper logger
@@ -56,10 +60,24 @@ func runProxy(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	sc := certclient.Wrap(c, cert)
+	genesis, err := commands.GetTrustedGenesis()
+	if err != nil {
+		return err
+	}
+	sc := certclient.WrapWithCache(c, cert, commands.GetCheckpointCache()).
+		WithGenesis(genesis)
+	sc.SetLogger(logger.With("module", "events"))
 	sc.Start()
 	r := routes(sc)
 
+	// keep the seeds up to date in the background
+	var updater *certifiers.Updater
+	if interval := viper.GetDuration(intervalFlag); interval > 0 {
+		updater = certifiers.NewUpdater(cert, interval)
+		updater.SetLogger(logger.With("module", "updater"))
+		updater.Start()
+	}
+
 	// build the handler...
 	mux := http.NewServeMux()
 	rpc.RegisterRPCFuncs(mux, r, logger)
@@ -75,6 +93,9 @@ func runProxy(cmd *cobra.Command, args []string) error {
 
 	cmn.TrapSignal(func() {
 		// TODO: close up shop
+		if updater != nil {
+			updater.Stop()
+		}
 	})
 
 	return nil
@@ -97,7 +118,8 @@ func routes(c client.Client) map[string]*rpc.RPCFunc {
 		"block":      rpc.NewRPCFunc(c.Block, "height"),
 		"commit":     rpc.NewRPCFunc(c.Commit, "height"),
 		"tx":         rpc.NewRPCFunc(c.Tx, "hash,prove"),
-		"validators": rpc.NewRPCFunc(c.Validators, ""),
+		"tx_search":  rpc.NewRPCFunc(searchTxs(c), "query"),
+		"validators": rpc.NewRPCFunc(c.Validators, "height"),
 
 		// broadcast API
 		"broadcast_tx_commit": rpc.NewRPCFunc(c.BroadcastTxCommit, "tx"),
@@ -0,0 +1,30 @@
+package proxy
+
+import (
+	"github.com/tendermint/tendermint/rpc/client"
+
+	proofcmd "github.com/tendermint/light-client/commands/proofs"
+	"github.com/tendermint/light-client/proofs"
+)
+
+// ResultTxSearch holds all txs matching a query, each with a proof
+// we validated against a certified header
+type ResultTxSearch struct {
+	Txs []proofs.TxProof `json:"txs"`
+}
+
+// searchTxs returns the handler for tx_search, c must verify all commits
+func searchTxs(c client.Client) func(string) (*ResultTxSearch, error) {
+	searcher := proofs.NewTxSearcher(c, proofcmd.TxTaggers)
+	return func(query string) (*ResultTxSearch, error) {
+		q, err := proofs.ParseTxQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		found, err := searcher.Search(q)
+		if err != nil {
+			return nil, err
+		}
+		return &ResultTxSearch{found}, nil
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/tendermint/go-wire/data"
 	"github.com/tendermint/tendermint/rpc/client"
 
-	certclient "github.com/tendermint/light-client/certifiers/client"
 	"github.com/tendermint/light-client/commands"
 )
 
@@ -40,14 +39,7 @@ func init() {
 }
 
 func getSecureNode() (client.Client, error) {
-	// First, connect a client
-	c := commands.GetNode()
-	cert, err := commands.GetCertifier()
-	if err != nil {
-		return nil, err
-	}
-	sc := certclient.Wrap(c, cert)
-	return sc, nil
+	return commands.GetSecureNode()
 }
 
 // printResult just writes the struct to the console, returns an error if it can't
@@ -11,6 +11,7 @@ func init() {
 	commitCmd.Flags().Int(FlagHeight, -1, "block height")
 	headersCmd.Flags().Int(FlagMin, -1, "minimum block height")
 	headersCmd.Flags().Int(FlagMax, -1, "maximum block height")
+	validatorsCmd.Flags().Int(FlagHeight, -1, "block height (skip to use latest block)")
 }
 
 var blockCmd = &cobra.Command{
@@ -73,3 +74,66 @@ func runHeaders(cmd *cobra.Command, args []string) error {
 	}
 	return printResult(headers)
 }
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Get the status of the node, validated against the latest headers",
+	RunE:  commands.RequireInit(runStatus),
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	c, err := getSecureNode()
+	if err != nil {
+		return err
+	}
+	status, err := c.Status()
+	if err != nil {
+		return err
+	}
+	return printResult(status)
+}
+
+var validatorsCmd = &cobra.Command{
+	Use:   "validators",
+	Short: "Get the validators at a given height, validated against the header",
+	RunE:  commands.RequireInit(runValidators),
+}
+
+func runValidators(cmd *cobra.Command, args []string) error {
+	c, err := getSecureNode()
+	if err != nil {
+		return err
+	}
+	var height *int
+	if h := viper.GetInt(FlagHeight); h > 0 {
+		height = &h
+	}
+	validators, err := c.Validators(height)
+	if err != nil {
+		return err
+	}
+	return printResult(validators)
+}
+
+var genesisCmd = &cobra.Command{
+	Use:   "genesis",
+	Short: "Get the genesis of the node, if it matches the one we trust",
+	Long: `Get the genesis of the node, if it matches the one we trust.
+
+We can only check the validators and hash of the genesis if init was
+given a --genesis file, otherwise we only check the chain id.
+`,
+	RunE: commands.RequireInit(runGenesis),
+}
+
+func runGenesis(cmd *cobra.Command, args []string) error {
+	c, err := getSecureNode()
+	if err != nil {
+		return err
+	}
+	genesis, err := c.Genesis()
+	if err != nil {
+		return err
+	}
+	return printResult(genesis)
+}
@@ -0,0 +1,81 @@
+package seeds
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/light-client/certifiers"
+	"github.com/tendermint/light-client/commands"
+)
+
+const (
+	keepFlag    = "keep"
+	changesFlag = "keep-changes"
+	expiredFlag = "expired"
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old seeds from the local store",
+	Long: `Prune deletes all seeds that none of the given policies keeps.
+
+You can keep the most recent seeds, the first seed after every
+validator set change, or all seeds within the trusting period.
+Combining policies keeps every seed that any of them keeps.
+The most recent seed is always kept.
+`,
+	RunE:         commands.RequireInit(pruneSeeds),
+	SilenceUsage: true,
+}
+
+func init() {
+	pruneCmd.Flags().Int(keepFlag, 0, "Keep this many of the most recent seeds")
+	pruneCmd.Flags().Bool(changesFlag, false, "Keep the first seed after every validator set change")
+	pruneCmd.Flags().Bool(expiredFlag, false, "Keep seeds within the trusting period, delete older ones")
+	pruneCmd.Flags().Bool(dryFlag, false, "Only show which seeds would be deleted")
+	RootCmd.AddCommand(pruneCmd)
+}
+
+func pruneSeeds(cmd *cobra.Command, args []string) error {
+	trust, _ := commands.GetProviders()
+	pruner, ok := trust.(certifiers.Pruner)
+	if !ok {
+		return errors.New("The local store does not support pruning")
+	}
+
+	policies := []certifiers.PrunePolicy{}
+	if n := viper.GetInt(keepFlag); n > 0 {
+		policies = append(policies, certifiers.KeepLast(n))
+	}
+	if viper.GetBool(changesFlag) {
+		policies = append(policies, certifiers.KeepValidatorChanges())
+	}
+	if viper.GetBool(expiredFlag) {
+		period := viper.GetDuration(commands.PeriodFlag)
+		if period <= 0 {
+			return errors.Errorf("--%s requires a --%s", expiredFlag, commands.PeriodFlag)
+		}
+		policies = append(policies, certifiers.KeepNewerThan(period, nil))
+	}
+	if len(policies) == 0 {
+		return errors.Errorf("Please select what to prune with --%s, --%s, or --%s",
+			keepFlag, changesFlag, expiredFlag)
+	}
+
+	dryRun := viper.GetBool(dryFlag)
+	pruned, err := certifiers.Prune(pruner, dryRun, policies...)
+	for _, s := range pruned {
+		fmt.Printf("%d: %X\n", s.Height(), s.Hash())
+	}
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Printf("Would delete %d seeds\n", len(pruned))
+	} else {
+		fmt.Printf("Deleted %d seeds\n", len(pruned))
+	}
+	return nil
+}
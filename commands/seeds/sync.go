@@ -0,0 +1,57 @@
+package seeds
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/light-client/certifiers"
+	"github.com/tendermint/light-client/commands"
+)
+
+const (
+	intervalFlag = "interval"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Verify every header up to the given height",
+	Long: `Sync downloads every header since the last seed we trust, and makes
+sure each one is linked to the one before.  This is slower than update,
+but verifies the full header chain.
+
+Seeds are stored every interval blocks, and on every validator change.
+`,
+	RunE:         commands.RequireInit(syncSeeds),
+	SilenceUsage: true,
+}
+
+func init() {
+	syncCmd.Flags().Int(heightFlag, 0, "Sync up to this height (default is latest)")
+	syncCmd.Flags().Int(intervalFlag, 1000, "Store a seed every this many blocks")
+	RootCmd.AddCommand(syncCmd)
+}
+
+func syncSeeds(cmd *cobra.Command, args []string) error {
+	cert, err := commands.GetCertifier()
+	if err != nil {
+		return err
+	}
+
+	h := viper.GetInt(heightFlag)
+	if h == 0 {
+		seed, err := certifiers.LatestSeed(cert.SeedSource)
+		if err != nil {
+			return err
+		}
+		h = seed.Height()
+	}
+	fmt.Printf("Syncing headers up to height: %d...\n", h)
+
+	stats, err := cert.SyncToHeight(h, viper.GetInt(intervalFlag))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Success! Verified %d headers, stored %d seeds\n", stats.Headers, stats.Stored)
+	return nil
+}
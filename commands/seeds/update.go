@@ -33,10 +33,10 @@ func updateSeed(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Trying to update to height: %d...\n", seed.Height())
 
 	// let the certifier do it's magic to update....
-	err = cert.Update(seed.Checkpoint, seed.Validators)
+	stats, err := cert.UpdateToHeight(seed.Height())
 	if err != nil {
 		return err
 	}
-	fmt.Println("Success!")
+	fmt.Printf("Success! Downloaded %d seeds, stored %d\n", stats.Fetched, stats.Stored)
 	return nil
 }
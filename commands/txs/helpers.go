@@ -57,7 +57,8 @@ func Sign(tx interface{}) (packet []byte, err error) {
 
 // SignAndPostTx does all work once we construct a proper struct
 // it validates the data, signs if needed, transforms to bytes,
-// and posts to the node.
+// and posts to the node.  We only return once we verified the tx
+// was committed.
 func SignAndPostTx(tx Validatable) (*ctypes.ResultBroadcastTxCommit, error) {
 	// validate tx client-side
 	err := tx.ValidateBasic()
@@ -71,8 +72,11 @@ func SignAndPostTx(tx Validatable) (*ctypes.ResultBroadcastTxCommit, error) {
 		return nil, err
 	}
 
-	// post the bytes
-	node := commands.GetNode()
+	// post the bytes, and prove they made it into a block
+	node, err := commands.GetSecureNode()
+	if err != nil {
+		return nil, err
+	}
 	return node.BroadcastTxCommit(packet)
 }
 
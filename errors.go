@@ -52,3 +52,56 @@ func ErrNoData() error {
 }
 
 //--------------------------------------------
+
+type errGenesisMismatch struct {
+	field        string
+	trusted, got string
+}
+
+func (e errGenesisMismatch) Error() string {
+	return fmt.Sprintf("Genesis %s doesn't match - trusted %s, got %s",
+		e.field, e.trusted, e.got)
+}
+
+// IsGenesisMismatchErr checks whether an error is due to a genesis
+// different from the one we trust
+func IsGenesisMismatchErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := errors.Cause(err).(errGenesisMismatch)
+	return ok
+}
+
+func ErrGenesisMismatch(field, trusted, got string) error {
+	return errors.WithStack(errGenesisMismatch{field, trusted, got})
+}
+
+//--------------------------------------------
+
+type errTxNotCommitted struct {
+	hash   []byte
+	height int
+	reason error
+}
+
+func (e errTxNotCommitted) Error() string {
+	return fmt.Sprintf("Cannot prove tx %X is in block %d: %v",
+		e.hash, e.height, e.reason)
+}
+
+// IsTxNotCommittedErr checks whether an error is due to a tx we cannot
+// prove was committed where the node claims it was
+func IsTxNotCommittedErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	_, ok := errors.Cause(err).(errTxNotCommitted)
+	return ok
+}
+
+func ErrTxNotCommitted(hash []byte, height int, reason error) error {
+	return errors.WithStack(errTxNotCommitted{hash, height, reason})
+}
+
+//--------------------------------------------
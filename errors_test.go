@@ -26,3 +26,23 @@ func TestErrorNoData(t *testing.T) {
 	assert.False(t, IsNoDataErr(e2))
 	assert.False(t, IsNoDataErr(nil))
 }
+
+func TestErrorGenesisMismatch(t *testing.T) {
+	e1 := ErrGenesisMismatch("chain id", "foo", "bar")
+	e1.Error()
+	assert.True(t, IsGenesisMismatchErr(e1))
+
+	e2 := errors.New("foobar")
+	assert.False(t, IsGenesisMismatchErr(e2))
+	assert.False(t, IsGenesisMismatchErr(nil))
+}
+
+func TestErrorTxNotCommitted(t *testing.T) {
+	e1 := ErrTxNotCommitted([]byte{1, 2}, 5, errors.New("missing"))
+	e1.Error()
+	assert.True(t, IsTxNotCommittedErr(e1))
+
+	e2 := errors.New("foobar")
+	assert.False(t, IsTxNotCommittedErr(e2))
+	assert.False(t, IsTxNotCommittedErr(nil))
+}
@@ -0,0 +1,72 @@
+package lightclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/tendermint/go-wire/data"
+	"github.com/tendermint/tendermint/types"
+)
+
+// TrustedGenesis is what we recorded about the genesis of the chain when
+// we initialized the client, so we can check what a node serves us later
+type TrustedGenesis struct {
+	ChainID string `json:"chain_id"`
+	// ValidatorHash is the hash of the initial validator set
+	ValidatorHash data.Bytes `json:"validator_hash"`
+	// Hash is the GenesisHash of the whole document (optional)
+	Hash data.Bytes `json:"hash"`
+}
+
+// NewTrustedGenesis records everything we need to verify this genesis later
+func NewTrustedGenesis(doc *types.GenesisDoc) (TrustedGenesis, error) {
+	hash, err := GenesisHash(doc)
+	return TrustedGenesis{
+		ChainID:       doc.ChainID,
+		ValidatorHash: doc.ValidatorHash(),
+		Hash:          hash,
+	}, err
+}
+
+// GenesisHash is the sha256 of the json encoding of the genesis doc.
+// As we encode the parsed document, formatting of the file doesn't matter.
+func GenesisHash(doc *types.GenesisDoc) ([]byte, error) {
+	bz, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	hash := sha256.Sum256(bz)
+	return hash[:], nil
+}
+
+// Verify makes sure the doc matches what we trust.  Empty hashes are
+// not checked.
+func (t TrustedGenesis) Verify(doc *types.GenesisDoc) error {
+	if doc == nil {
+		return errors.New("Missing genesis doc")
+	}
+	if doc.ChainID != t.ChainID {
+		return ErrGenesisMismatch("chain id", t.ChainID, doc.ChainID)
+	}
+	if len(t.ValidatorHash) > 0 {
+		vhash := doc.ValidatorHash()
+		if !bytes.Equal(vhash, t.ValidatorHash) {
+			return ErrGenesisMismatch("validator hash",
+				hex.EncodeToString(t.ValidatorHash), hex.EncodeToString(vhash))
+		}
+	}
+	if len(t.Hash) > 0 {
+		hash, err := GenesisHash(doc)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(hash, t.Hash) {
+			return ErrGenesisMismatch("genesis hash",
+				hex.EncodeToString(t.Hash), hex.EncodeToString(hash))
+		}
+	}
+	return nil
+}
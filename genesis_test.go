@@ -0,0 +1,65 @@
+package lightclient
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	crypto "github.com/tendermint/go-crypto"
+	"github.com/tendermint/tendermint/types"
+)
+
+func genDoc(chainID string, vals int) *types.GenesisDoc {
+	doc := &types.GenesisDoc{
+		GenesisTime: time.Now(),
+		ChainID:     chainID,
+	}
+	for i := 0; i < vals; i++ {
+		doc.Validators = append(doc.Validators, types.GenesisValidator{
+			PubKey: crypto.GenPrivKeyEd25519().PubKey(),
+			Amount: 10,
+		})
+	}
+	return doc
+}
+
+func TestTrustedGenesis(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	doc := genDoc("genesis-test", 3)
+	trust, err := NewTrustedGenesis(doc)
+	require.Nil(err, "%+v", err)
+	assert.Nil(trust.Verify(doc))
+
+	// what we get over the wire is the same
+	bz, err := json.MarshalIndent(doc, "", "    ")
+	require.Nil(err)
+	loaded, err := types.GenesisDocFromJSON(bz)
+	require.Nil(err, "%+v", err)
+	assert.Nil(trust.Verify(loaded))
+
+	// other chain
+	other := *doc
+	other.ChainID = "foobar"
+	err = trust.Verify(&other)
+	assert.True(IsGenesisMismatchErr(err), "%+v", err)
+
+	// other validators
+	other = *doc
+	other.Validators = genDoc("genesis-test", 3).Validators
+	err = trust.Verify(&other)
+	assert.True(IsGenesisMismatchErr(err), "%+v", err)
+
+	// other app hash
+	other = *doc
+	other.AppHash = []byte("other")
+	err = trust.Verify(&other)
+	assert.True(IsGenesisMismatchErr(err), "%+v", err)
+
+	// if we didn't record the hash, we only check what we have
+	trust.Hash = nil
+	assert.Nil(trust.Verify(&other))
+	assert.NotNil(trust.Verify(nil))
+}
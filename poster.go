@@ -9,7 +9,8 @@ import (
 // Poster combines KeyStore and Node to process a Signable and deliver it to tendermint
 // returning the results from the tendermint node, once the transaction is processed.
 //
-// Only handles single signatures
+// Only handles single signatures.  Pass in a certifiers/client.Wrapper as
+// server, to only get results for txs that provably made it into a block.
 type Poster struct {
 	server client.ABCIClient
 	signer keys.Signer
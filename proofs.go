@@ -10,6 +10,18 @@ type Prover interface {
 	Unmarshal([]byte) (Proof, error)
 }
 
+// RangeProver is a Prover that can also prove the complete set of
+// key-value pairs in a range of keys [start, end), so we can be sure
+// nothing was left out.
+type RangeProver interface {
+	Prover
+	// GetRange returns all keys in [start, end) for the given block height
+	// An empty end means no upper bound.
+	// The prover should accept h=0 for latest height
+	GetRange(start, end []byte, h uint64) (Proof, error)
+	UnmarshalRange([]byte) (Proof, error)
+}
+
 // Proof is a generic interface for data along with the cryptographic proof
 // of it's validity, tied to a checkpoint.
 //
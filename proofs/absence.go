@@ -0,0 +1,218 @@
+package proofs
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	wire "github.com/tendermint/go-wire"
+	data "github.com/tendermint/go-wire/data"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/tendermint/rpc/client"
+)
+
+var _ lc.Prover = AbsenceProver{}
+var _ lc.Proof = AppAbsenceProof{}
+
+// we query the node a few times, hoping to get all data from the same block
+const queryRetries = 5
+
+// AbsenceProver provides negative proofs for the abciapp (this key is not set).
+//
+// The node cannot prove absence directly, so we find the two keys
+// surrounding the missing key, and prove they are neighbors in the tree.
+type AbsenceProver struct {
+	node client.Client
+	app  AppProver
+}
+
+func NewAbsenceProver(node client.Client) AbsenceProver {
+	return AbsenceProver{
+		node: node,
+		app:  NewAppProver(node),
+	}
+}
+
+// Get tries to download proofs that this key is not set in the app state.
+//
+// Returns an error if the key is actually set.
+func (a AbsenceProver) Get(key []byte, h uint64) (lc.Proof, error) {
+	var proof AppAbsenceProof
+	var err error
+
+	// if a new block comes between queries, we need to try again
+	for i := 0; i < queryRetries; i++ {
+		proof, err = a.get(key)
+		if !lc.IsHeightMismatchErr(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if h != 0 && h != proof.Height {
+		return nil, lc.ErrHeightMismatch(int(h), int(proof.Height))
+	}
+	return proof, nil
+}
+
+func (a AbsenceProver) get(key []byte) (proof AppAbsenceProof, err error) {
+	idx, err := queryPosition(a.node, key)
+	if err != nil {
+		return proof, err
+	}
+
+	proof.Key = key
+	if idx > 0 {
+		proof.Left, err = a.app.getAt(idx - 1)
+		if err != nil {
+			return proof, err
+		}
+	}
+	proof.Right, err = a.app.getAt(idx)
+	if err != nil {
+		return proof, err
+	}
+
+	// all proofs must come from the same block
+	switch {
+	case proof.Left != nil && proof.Right != nil:
+		if proof.Left.Height != proof.Right.Height {
+			return proof, lc.ErrHeightMismatch(int(proof.Left.Height),
+				int(proof.Right.Height))
+		}
+		proof.Height = proof.Left.Height
+	case proof.Left != nil:
+		proof.Height = proof.Left.Height
+	case proof.Right != nil:
+		proof.Height = proof.Right.Height
+	default:
+		return proof, lc.ErrNoData()
+	}
+	return proof, nil
+}
+
+func (a AbsenceProver) Unmarshal(data []byte) (lc.Proof, error) {
+	var proof AppAbsenceProof
+	err := errors.WithStack(wire.ReadBinaryBytes(data, &proof))
+	return proof, err
+}
+
+// queryPosition returns the index in the tree where this key would be stored.
+// Returns an error if the key is actually present.
+func queryPosition(node client.Client, key []byte) (int, error) {
+	idx, exists, err := queryIndex(node, key)
+	if err == nil && exists {
+		err = errors.Errorf("Key %X is set, cannot prove absence", key)
+	}
+	return idx, err
+}
+
+// queryIndex returns the index in the tree where this key is, or would be,
+// stored, and whether it is present
+func queryIndex(node client.Client, key []byte) (int, bool, error) {
+	resp, err := node.ABCIQuery("/key", key, false)
+	if err != nil {
+		return 0, false, err
+	}
+	if !resp.Code.IsOK() {
+		return 0, false, errors.Errorf("Query error %d: %s", resp.Code, resp.Code.String())
+	}
+	return int(resp.Index), len(resp.Value) != 0, nil
+}
+
+// queryKeyAt returns the key stored at the given index in the tree,
+// or nil if the index is past the end of the tree
+func queryKeyAt(node client.Client, idx int) ([]byte, error) {
+	buf := make([]byte, 8)
+	wire.PutInt64(buf, int64(idx))
+	resp, err := node.ABCIQuery("/index", buf, false)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Code.IsOK() {
+		return nil, errors.Errorf("Query error %d: %s", resp.Code, resp.Code.String())
+	}
+	return resp.Key, nil
+}
+
+// AppAbsenceProof proves that a key is not set at a given height.
+//
+// It contains proofs for the closest keys on either side of Key,
+// which must be neighbors in the tree. If Key is before the first key
+// or after the last key in the tree, only one side is present.
+type AppAbsenceProof struct {
+	Height uint64
+	Key    data.Bytes
+	Left   *AppProof
+	Right  *AppProof
+}
+
+// Data is always empty, as there is no value for this key
+func (p AppAbsenceProof) Data() []byte {
+	return nil
+}
+
+func (p AppAbsenceProof) BlockHeight() uint64 {
+	return p.Height
+}
+
+func (p AppAbsenceProof) Validate(check lc.Checkpoint) error {
+	if uint64(check.Height()) != p.Height {
+		return lc.ErrHeightMismatch(int(p.Height), check.Height())
+	}
+
+	// an empty tree doesn't contain anything
+	if p.Left == nil && p.Right == nil {
+		if len(check.Header.AppHash) == 0 {
+			return nil
+		}
+		return errors.New("Absence proof without neighbors")
+	}
+
+	var left, right, total int
+	if p.Left != nil {
+		if bytes.Compare(p.Left.Key, p.Key) >= 0 {
+			return errors.Errorf("Left neighbor %X not before key %X", p.Left.Key, p.Key)
+		}
+		err := p.Left.Validate(check)
+		if err != nil {
+			return err
+		}
+		left, total, err = p.Left.position()
+		if err != nil {
+			return err
+		}
+	}
+	if p.Right != nil {
+		if bytes.Compare(p.Right.Key, p.Key) <= 0 {
+			return errors.Errorf("Right neighbor %X not after key %X", p.Right.Key, p.Key)
+		}
+		err := p.Right.Validate(check)
+		if err != nil {
+			return err
+		}
+		right, total, err = p.Right.position()
+		if err != nil {
+			return err
+		}
+	}
+
+	// now make sure there is no room for the key between the neighbors
+	switch {
+	case p.Left == nil && right != 0:
+		return errors.Errorf("Right neighbor %X is not the first key", p.Right.Key)
+	case p.Right == nil && left != total-1:
+		return errors.Errorf("Left neighbor %X is not the last key", p.Left.Key)
+	case p.Left != nil && p.Right != nil && right != left+1:
+		return errors.Errorf("Keys %X and %X are not neighbors", p.Left.Key, p.Right.Key)
+	}
+
+	// LGTM!
+	return nil
+}
+
+func (p AppAbsenceProof) Marshal() ([]byte, error) {
+	data := wire.BinaryBytes(p)
+	return data, nil
+}
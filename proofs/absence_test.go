@@ -0,0 +1,69 @@
+package proofs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/light-client/proofs"
+	merktest "github.com/tendermint/merkleeyes/testutil"
+	cmn "github.com/tendermint/tmlibs/common"
+)
+
+func TestAbsenceProofs(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cl := getLocalClient()
+	prover := proofs.NewAbsenceProver(cl)
+	time.Sleep(200 * time.Millisecond)
+
+	// store a few values, so we have neighbors to prove with
+	var k []byte
+	for i := 0; i < 5; i++ {
+		var tx []byte
+		k, _, tx = merktest.MakeTxKV()
+		br, err := cl.BroadcastTxCommit(tx)
+		require.Nil(err, "%+v", err)
+		require.EqualValues(0, br.CheckTx.Code)
+		require.EqualValues(0, br.DeliverTx.Code)
+	}
+
+	// we cannot prove a key that is set is missing
+	_, err := prover.Get(k, 0)
+	assert.NotNil(err)
+
+	// but we can for a key that was never set
+	missing := cmn.RandBytes(16)
+	pr, err := prover.Get(missing, 0)
+	require.Nil(err, "%+v", err)
+	check := getCheckForHeight(t, cl, int(pr.BlockHeight()))
+
+	err = pr.Validate(check)
+	assert.Nil(err, "%+v", err)
+	assert.Empty(pr.Data())
+
+	abs, ok := pr.(proofs.AppAbsenceProof)
+	if assert.True(ok) {
+		assert.EqualValues(missing, abs.Key)
+		assert.True(abs.Left != nil || abs.Right != nil)
+
+		// claiming the absence of a key that is set must fail
+		if abs.Left != nil {
+			bad := abs
+			bad.Key = abs.Left.Key
+			assert.NotNil(bad.Validate(check))
+		}
+		// as must skipping a neighbor
+		if abs.Left != nil && abs.Right != nil {
+			bad := abs
+			bad.Left = nil
+			bad.Key = abs.Right.Key[:len(abs.Right.Key)-1]
+			assert.NotNil(bad.Validate(check))
+		}
+	}
+
+	// make sure we read/write properly (mutations may just prove
+	// another absent key, so we don't check them here)
+	testSerialization(t, prover, pr, check, 0)
+}
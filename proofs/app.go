@@ -17,7 +17,7 @@ const appLimit = 1000 * 1000
 
 // AppProver provides positive proofs of key-value pairs in the abciapp.
 //
-// TODO: also support negative proofs (this key is not set)
+// Negative proofs (this key is not set) are provided by AbsenceProver
 type AppProver struct {
 	node client.Client
 }
@@ -99,3 +99,27 @@ func (p AppProof) Marshal() ([]byte, error) {
 	data := wire.BinaryBytes(p)
 	return data, nil
 }
+
+// position returns the index of the proven leaf in the iavl tree, along
+// with the total number of leaves in the tree.
+//
+// Every inner node on the path carries the size of its subtree, which is
+// part of the hash, so this is as trustworthy as the proof itself, once
+// it has been validated.
+func (p AppProof) position() (index, total int, err error) {
+	proof, err := iavl.ReadProof(p.Proof)
+	if err != nil {
+		return 0, 0, errors.WithStack(err)
+	}
+
+	// walk from the leaf up to the root, every time we are the right child,
+	// all leaves of the left sibling come before us
+	size := 1
+	for _, node := range proof.InnerNodes {
+		if len(node.Left) != 0 {
+			index += node.Size - size
+		}
+		size = node.Size
+	}
+	return index, size, nil
+}
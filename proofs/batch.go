@@ -0,0 +1,151 @@
+package proofs
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	lc "github.com/tendermint/light-client"
+)
+
+// batchRetries is how often we query keys again, if the chain moved on
+// while we were getting the batch
+const batchRetries = 3
+
+// batchWorkers is how many keys we query at the same time
+const batchWorkers = 8
+
+// BatchResult holds proofs for many keys, all at the same height, so
+// we only need one checkpoint to validate them.
+//
+// All maps are indexed by string(key).  Every key is in exactly one
+// of them.
+type BatchResult struct {
+	Height uint64
+	Proofs map[string]AppProof
+	Absent map[string]AppAbsenceProof
+	Errors map[string]error
+}
+
+func newBatchResult(h uint64) BatchResult {
+	return BatchResult{
+		Height: h,
+		Proofs: map[string]AppProof{},
+		Absent: map[string]AppAbsenceProof{},
+		Errors: map[string]error{},
+	}
+}
+
+// keys returns all keys in the result
+func (b BatchResult) keys() [][]byte {
+	res := make([][]byte, 0, len(b.Proofs)+len(b.Absent)+len(b.Errors))
+	for k := range b.Proofs {
+		res = append(res, []byte(k))
+	}
+	for k := range b.Absent {
+		res = append(res, []byte(k))
+	}
+	for k := range b.Errors {
+		res = append(res, []byte(k))
+	}
+	return res
+}
+
+// batchEntry is what we got for one key, either an AppProof, an
+// AppAbsenceProof or an error
+type batchEntry struct {
+	key   []byte
+	proof lc.Proof
+	err   error
+}
+
+// GetBatch gets proofs for all keys at the latest height.  Keys that are
+// not set get an AppAbsenceProof.
+//
+// The node can only answer queries at the latest block, so if the chain
+// moves on while we query, we start over for all keys we already have.
+// Keys we cannot prove at Height get an entry in Errors.  The proofs
+// still have to be validated against a certified checkpoint at Height.
+func (a AppProver) GetBatch(keys [][]byte) BatchResult {
+	res := newBatchResult(0)
+	absence := NewAbsenceProver(a.node)
+
+	pending := keys
+	for i := 0; i <= batchRetries && len(pending) > 0; i++ {
+		retry := [][]byte{}
+		for _, e := range a.queryBatch(absence, pending) {
+			k := string(e.key)
+			if e.err != nil {
+				res.Errors[k] = e.err
+				continue
+			}
+
+			h := e.proof.BlockHeight()
+			if h > res.Height {
+				// the chain moved on, so we query everything we have again,
+				// including the keys that failed at the old height
+				retry = append(retry, res.keys()...)
+				res = newBatchResult(h)
+			}
+			if h < res.Height {
+				retry = append(retry, e.key)
+				continue
+			}
+			switch p := e.proof.(type) {
+			case AppProof:
+				res.Proofs[k] = p
+			case AppAbsenceProof:
+				res.Absent[k] = p
+			}
+		}
+		pending = retry
+	}
+
+	// whatever is left, we could not get at the proper height
+	for _, key := range pending {
+		res.Errors[string(key)] = errors.Errorf("Cannot get key %X at height %d",
+			key, res.Height)
+	}
+	return res
+}
+
+// queryBatch gets a proof for every key, batchWorkers at a time
+func (a AppProver) queryBatch(absence AbsenceProver, keys [][]byte) []batchEntry {
+	res := make([]batchEntry, len(keys))
+	sem := make(chan struct{}, batchWorkers)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key []byte) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			pr, err := a.Get(key, 0)
+			if lc.IsNoDataErr(err) {
+				pr, err = absence.Get(key, 0)
+			}
+			res[i] = batchEntry{key, pr, err}
+		}(i, key)
+	}
+	wg.Wait()
+	return res
+}
+
+// Validate checks all proofs against the checkpoint, and moves
+// the invalid ones to Errors
+func (b BatchResult) Validate(check lc.Checkpoint) {
+	for k, proof := range b.Proofs {
+		err := proof.Validate(check)
+		if err != nil {
+			delete(b.Proofs, k)
+			b.Errors[k] = err
+		}
+	}
+	for k, proof := range b.Absent {
+		err := proof.Validate(check)
+		if err != nil {
+			delete(b.Absent, k)
+			b.Errors[k] = err
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package proofs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/light-client/proofs"
+	merktest "github.com/tendermint/merkleeyes/testutil"
+)
+
+func TestAppBatchProofs(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cl := getLocalClient()
+	prover := proofs.NewAppProver(cl)
+
+	// store a few keys in different blocks
+	keys := [][]byte{}
+	values := map[string][]byte{}
+	for i := 0; i < 5; i++ {
+		k, v, tx := merktest.MakeTxKV()
+		br, err := cl.BroadcastTxCommit(tx)
+		require.Nil(err, "%+v", err)
+		require.EqualValues(0, br.DeliverTx.Code)
+		keys = append(keys, k)
+		values[string(k)] = v
+	}
+	missing := []byte("no-such-key")
+	keys = append(keys, missing)
+
+	res := prover.GetBatch(keys)
+	require.Equal(5, len(res.Proofs), "%+v", res.Errors)
+	require.Equal(0, len(res.Errors), "%+v", res.Errors)
+	// the missing key is proven absent
+	require.Equal(1, len(res.Absent))
+	absent, ok := res.Absent[string(missing)]
+	require.True(ok)
+	assert.Equal(res.Height, absent.Height)
+
+	// all at one height, so one header validates them all
+	check := getCheckForHeight(t, cl, int(res.Height))
+	for k, pr := range res.Proofs {
+		assert.Equal(res.Height, pr.Height)
+		assert.EqualValues(values[k], pr.Value)
+	}
+	res.Validate(check)
+	assert.Equal(5, len(res.Proofs))
+	assert.Equal(1, len(res.Absent))
+	assert.Equal(0, len(res.Errors))
+
+	// and a different header invalidates them
+	res = prover.GetBatch([][]byte{keys[0], keys[1], missing})
+	require.Equal(2, len(res.Proofs))
+	require.Equal(1, len(res.Absent))
+	res.Validate(getCheckForHeight(t, cl, 1))
+	assert.Equal(0, len(res.Proofs))
+	assert.Equal(0, len(res.Absent))
+	assert.Equal(3, len(res.Errors))
+	for _, err := range res.Errors {
+		assert.True(lc.IsHeightMismatchErr(err), "%+v", err)
+	}
+}
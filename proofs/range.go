@@ -0,0 +1,246 @@
+package proofs
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	wire "github.com/tendermint/go-wire"
+	data "github.com/tendermint/go-wire/data"
+	lc "github.com/tendermint/light-client"
+)
+
+var _ lc.RangeProver = AppProver{}
+var _ lc.Proof = AppRangeProof{}
+
+// we limit the number of keys in one range proof, ask for smaller
+// ranges if you need more
+const rangeLimit = 1000
+
+// GetRange downloads proofs for every key-value pair in [start, end),
+// along with the neighbors just outside of the range, so we can prove
+// the result set is complete. An empty end means no upper bound.
+//
+// Use PrefixEnd to get all keys with a given prefix.
+func (a AppProver) GetRange(start, end []byte, h uint64) (lc.Proof, error) {
+	if len(end) != 0 && bytes.Compare(start, end) >= 0 {
+		return nil, errors.Errorf("Invalid range %X - %X", start, end)
+	}
+
+	var proof AppRangeProof
+	var err error
+
+	// if a new block comes between queries, we need to try again
+	for i := 0; i < queryRetries; i++ {
+		proof, err = a.getRange(start, end)
+		if !lc.IsHeightMismatchErr(err) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if h != 0 && h != proof.Height {
+		return nil, lc.ErrHeightMismatch(int(h), int(proof.Height))
+	}
+	return proof, nil
+}
+
+func (a AppProver) getRange(start, end []byte) (proof AppRangeProof, err error) {
+	idx, _, err := queryIndex(a.node, start)
+	if err != nil {
+		return proof, err
+	}
+	proof.Start, proof.End = start, end
+
+	if idx > 0 {
+		proof.Left, err = a.getAt(idx - 1)
+		if err != nil {
+			return proof, err
+		}
+	}
+
+	// walk through the tree until we leave the range
+	for ; ; idx++ {
+		var pr *AppProof
+		pr, err = a.getAt(idx)
+		if err != nil || pr == nil {
+			break
+		}
+		if len(end) != 0 && bytes.Compare(pr.Key, end) >= 0 {
+			proof.Right = pr
+			break
+		}
+		if len(proof.Items) >= rangeLimit {
+			return proof, errors.Errorf("More than %d keys in range", rangeLimit)
+		}
+		proof.Items = append(proof.Items, *pr)
+	}
+	if err != nil {
+		return proof, err
+	}
+
+	// all proofs must come from the same block
+	proofs := proof.all()
+	if len(proofs) == 0 {
+		return proof, lc.ErrNoData()
+	}
+	proof.Height = proofs[0].Height
+	for _, pr := range proofs[1:] {
+		if pr.Height != proof.Height {
+			return proof, lc.ErrHeightMismatch(int(proof.Height), int(pr.Height))
+		}
+	}
+	return proof, nil
+}
+
+// getAt returns the proof for the key stored at the given index,
+// or nil if there is no such key
+func (a AppProver) getAt(idx int) (*AppProof, error) {
+	key, err := queryKeyAt(a.node, idx)
+	if err != nil || len(key) == 0 {
+		return nil, err
+	}
+	pr, err := a.Get(key, 0)
+	if err != nil {
+		return nil, err
+	}
+	proof := pr.(AppProof)
+	return &proof, nil
+}
+
+func (a AppProver) UnmarshalRange(data []byte) (lc.Proof, error) {
+	var proof AppRangeProof
+	err := errors.WithStack(wire.ReadBinaryBytes(data, &proof))
+	return proof, err
+}
+
+// PrefixEnd returns the first key after all keys with the given prefix,
+// for use as the end of a range. Returns nil (no upper bound) if there
+// is no such key.
+func PrefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// KeyValue is one entry in the result of a range query
+type KeyValue struct {
+	Key   data.Bytes `json:"key"`
+	Value data.Bytes `json:"value"`
+}
+
+// AppRangeProof contains all key-value pairs in [Start, End) at a
+// given height.
+//
+// Left and Right are the neighbors just outside of the range (if any),
+// and together with Items they must form a gap-free sequence of leaves
+// in the tree, which proves nothing was left out.
+type AppRangeProof struct {
+	Height uint64
+	Start  data.Bytes
+	End    data.Bytes
+	Left   *AppProof
+	Right  *AppProof
+	Items  []AppProof
+}
+
+// Pairs returns all key-value pairs in the range, sorted by key
+func (p AppRangeProof) Pairs() []KeyValue {
+	res := make([]KeyValue, len(p.Items))
+	for i, item := range p.Items {
+		res[i] = KeyValue{Key: item.Key, Value: item.Value}
+	}
+	return res
+}
+
+// Data returns the go-wire encoded Pairs
+func (p AppRangeProof) Data() []byte {
+	return wire.BinaryBytes(p.Pairs())
+}
+
+func (p AppRangeProof) BlockHeight() uint64 {
+	return p.Height
+}
+
+// all returns the proofs for all leaves covered by this range, in order
+func (p AppRangeProof) all() []AppProof {
+	res := make([]AppProof, 0, len(p.Items)+2)
+	if p.Left != nil {
+		res = append(res, *p.Left)
+	}
+	res = append(res, p.Items...)
+	if p.Right != nil {
+		res = append(res, *p.Right)
+	}
+	return res
+}
+
+func (p AppRangeProof) Validate(check lc.Checkpoint) error {
+	if uint64(check.Height()) != p.Height {
+		return lc.ErrHeightMismatch(int(p.Height), check.Height())
+	}
+
+	// make sure everything is in the place it claims to be
+	if p.Left != nil && bytes.Compare(p.Left.Key, p.Start) >= 0 {
+		return errors.Errorf("Left neighbor %X not before range", p.Left.Key)
+	}
+	if p.Right != nil && (len(p.End) == 0 || bytes.Compare(p.Right.Key, p.End) < 0) {
+		return errors.Errorf("Right neighbor %X not after range", p.Right.Key)
+	}
+	for _, item := range p.Items {
+		if bytes.Compare(item.Key, p.Start) < 0 ||
+			(len(p.End) != 0 && bytes.Compare(item.Key, p.End) >= 0) {
+			return errors.Errorf("Key %X outside of range", item.Key)
+		}
+	}
+
+	// an empty tree doesn't contain anything
+	proofs := p.all()
+	if len(proofs) == 0 {
+		if len(check.Header.AppHash) == 0 {
+			return nil
+		}
+		return errors.New("Range proof without any keys")
+	}
+
+	// now make sure all leaves follow each other with no gaps
+	var last, total int
+	for i, pr := range proofs {
+		err := pr.Validate(check)
+		if err != nil {
+			return err
+		}
+		var idx int
+		idx, total, err = pr.position()
+		if err != nil {
+			return err
+		}
+		if i > 0 && idx != last+1 {
+			return errors.Errorf("Keys missing before %X", pr.Key)
+		}
+		last = idx
+	}
+
+	first, _, _ := proofs[0].position()
+	if p.Left == nil && first != 0 {
+		return errors.New("Keys missing at start of range")
+	}
+	if p.Right == nil && last != total-1 {
+		return errors.New("Keys missing at end of range")
+	}
+
+	// LGTM!
+	return nil
+}
+
+func (p AppRangeProof) Marshal() ([]byte, error) {
+	data := wire.BinaryBytes(p)
+	return data, nil
+}
@@ -0,0 +1,110 @@
+package proofs_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/light-client/proofs"
+	merktest "github.com/tendermint/merkleeyes/testutil"
+)
+
+func TestPrefixEnd(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		prefix, end []byte
+	}{
+		{[]byte{1, 2, 3}, []byte{1, 2, 4}},
+		{[]byte{1, 2, 0xff}, []byte{1, 3}},
+		{[]byte{0xff, 0xff}, nil},
+		{[]byte{}, nil},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(tc.end, proofs.PrefixEnd(tc.prefix), "%X", tc.prefix)
+	}
+}
+
+func TestRangeProofs(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cl := getLocalClient()
+	prover := proofs.NewAppProver(cl)
+	time.Sleep(200 * time.Millisecond)
+
+	// store some keys, and ask for the range covering them all
+	var start, end []byte
+	keys := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		k, _, tx := merktest.MakeTxKV()
+		br, err := cl.BroadcastTxCommit(tx)
+		require.Nil(err, "%+v", err)
+		require.EqualValues(0, br.CheckTx.Code)
+		require.EqualValues(0, br.DeliverTx.Code)
+		keys[string(k)] = true
+		if start == nil || bytes.Compare(k, start) < 0 {
+			start = k
+		}
+		if end == nil || bytes.Compare(k, end) > 0 {
+			end = k
+		}
+	}
+	end = proofs.PrefixEnd(end)
+
+	pr, err := prover.GetRange(start, end, 0)
+	require.Nil(err, "%+v", err)
+	check := getCheckForHeight(t, cl, int(pr.BlockHeight()))
+
+	err = pr.Validate(check)
+	assert.Nil(err, "%+v", err)
+
+	// other tests may have stored keys in this range as well
+	rpr, ok := pr.(proofs.AppRangeProof)
+	require.True(ok)
+	pairs := rpr.Pairs()
+	require.True(len(pairs) >= len(keys))
+	found := 0
+	for i, p := range pairs {
+		if keys[string(p.Key)] {
+			found++
+		}
+		if i > 0 {
+			assert.True(bytes.Compare(pairs[i-1].Key, p.Key) < 0)
+		}
+	}
+	assert.Equal(len(keys), found)
+
+	// leaving out an item breaks the proof
+	bad := rpr
+	bad.Items = append([]proofs.AppProof{}, rpr.Items[1:]...)
+	assert.NotNil(bad.Validate(check))
+
+	// as does leaving out the neighbor
+	if rpr.Right != nil {
+		bad = rpr
+		bad.Right = nil
+		assert.NotNil(bad.Validate(check))
+	}
+
+	// a range with no keys still proves it is empty
+	empty := append([]byte{}, start...)
+	pr, err = prover.GetRange(append(empty, 0), append(empty, 0, 0), 0)
+	require.Nil(err, "%+v", err)
+	check = getCheckForHeight(t, cl, int(pr.BlockHeight()))
+	err = pr.Validate(check)
+	assert.Nil(err, "%+v", err)
+	rpr, ok = pr.(proofs.AppRangeProof)
+	if assert.True(ok) {
+		assert.Empty(rpr.Pairs())
+	}
+
+	// and survives serialization
+	data, err := pr.Marshal()
+	require.Nil(err, "%+v", err)
+	npr, err := prover.UnmarshalRange(data)
+	require.Nil(err, "%+v", err)
+	assert.Nil(npr.Validate(check))
+}
@@ -0,0 +1,262 @@
+package proofs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	lc "github.com/tendermint/light-client"
+	"github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tendermint/types"
+)
+
+// we don't scan more blocks than this for one query
+const searchLimit = 1000
+
+// heightTag is the only tag we understand without a Tagger
+const heightTag = "height"
+
+// Tagger extracts app-specific tags (like the sender) from a tx,
+// so we can search for them.  It returns an error for txs it doesn't
+// understand.
+type Tagger interface {
+	Tags(tx []byte) (map[string]string, error)
+}
+
+// HashTagger tags every tx with its hash (as upper case hex), so we can
+// always search for a known tx
+type HashTagger struct{}
+
+func (HashTagger) Tags(tx []byte) (map[string]string, error) {
+	return map[string]string{"hash": fmt.Sprintf("%X", types.Tx(tx).Hash())}, nil
+}
+
+type namedTagger struct {
+	app    string
+	tagger Tagger
+}
+
+// Taggers holds the taggers of all apps, in the order they were
+// registered
+type Taggers struct {
+	taggers []namedTagger
+}
+
+func NewTaggers() *Taggers {
+	return &Taggers{}
+}
+
+// Register adds this app to the taggers we try on every tx.  Registering
+// the same app again replaces its tagger.
+func (t *Taggers) Register(app string, tagger Tagger) {
+	for i, nt := range t.taggers {
+		if nt.app == app {
+			t.taggers[i].tagger = tagger
+			return
+		}
+	}
+	t.taggers = append(t.taggers, namedTagger{app, tagger})
+}
+
+// Len returns how many apps registered a tagger
+func (t *Taggers) Len() int {
+	return len(t.taggers)
+}
+
+// Tags merges the tags from all taggers that understand this tx.  If two
+// taggers set the same tag, the one registered first wins.
+//
+// Returns nil if no tagger understands the tx
+func (t *Taggers) Tags(tx []byte) map[string]string {
+	var res map[string]string
+	for _, nt := range t.taggers {
+		tags, err := nt.tagger.Tags(tx)
+		if err != nil {
+			continue
+		}
+		if res == nil {
+			res = map[string]string{}
+		}
+		for k, v := range tags {
+			if _, ok := res[k]; !ok {
+				res[k] = v
+			}
+		}
+	}
+	return res
+}
+
+// TxQuery selects all txs in [MinHeight, MaxHeight] with the given tags.
+// MaxHeight of 0 means the latest block.
+type TxQuery struct {
+	MinHeight int               `json:"min_height"`
+	MaxHeight int               `json:"max_height"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// ParseTxQuery reads queries like "height>=10 AND height<20 AND sender=ABCD"
+//
+// We support =, <, <=, >, >= on the height, and only = on other tags.
+func ParseTxQuery(query string) (TxQuery, error) {
+	q := TxQuery{Tags: map[string]string{}}
+	for _, cond := range strings.Split(query, " AND ") {
+		cond = strings.TrimSpace(cond)
+		if cond == "" {
+			continue
+		}
+		i := strings.IndexAny(cond, "<>=")
+		if i <= 0 {
+			return q, errors.Errorf("Invalid condition '%s'", cond)
+		}
+		tag := strings.TrimSpace(cond[:i])
+		op := cond[i : i+1]
+		rest := cond[i+1:]
+		if strings.HasPrefix(rest, "=") && op != "=" {
+			op += "="
+			rest = rest[1:]
+		}
+		value := strings.Trim(strings.TrimSpace(rest), "'")
+
+		if tag != heightTag {
+			if op != "=" {
+				return q, errors.Errorf("Can only compare %s with =", tag)
+			}
+			q.Tags[tag] = value
+			continue
+		}
+
+		h, err := strconv.Atoi(value)
+		if err != nil {
+			return q, errors.Errorf("Invalid height '%s'", value)
+		}
+		// every condition narrows the range, heights start at 1 and
+		// MaxHeight 0 would mean the latest block
+		var lower, upper int
+		switch op {
+		case "=":
+			lower, upper = h, h
+		case ">":
+			lower = h + 1
+		case ">=":
+			lower = h
+		case "<":
+			upper = h - 1
+		case "<=":
+			upper = h
+		}
+		if lower < 0 || upper < 0 || (lower == 0 && upper == 0) {
+			return q, errors.Errorf("Invalid condition '%s', heights start at 1", cond)
+		}
+		if lower > q.MinHeight {
+			q.MinHeight = lower
+		}
+		if upper > 0 && (q.MaxHeight == 0 || upper < q.MaxHeight) {
+			q.MaxHeight = upper
+		}
+	}
+	if q.MaxHeight > 0 && q.MinHeight > q.MaxHeight {
+		return q, errors.Errorf("No height matches '%s'", query)
+	}
+	return q, nil
+}
+
+// Match returns true if all tags in the query are set in tags
+func (q TxQuery) Match(tags map[string]string) bool {
+	for k, v := range q.Tags {
+		if tags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// TxSearcher finds all txs matching a query, and returns them
+// with their proofs
+type TxSearcher struct {
+	node    client.Client
+	taggers *Taggers
+}
+
+// NewTxSearcher searches the blocks from node.  node must certify the
+// commits it returns (e.g. a certifiers/client.Wrapper), as we check
+// every block against them.
+func NewTxSearcher(node client.Client, taggers *Taggers) TxSearcher {
+	return TxSearcher{node: node, taggers: taggers}
+}
+
+// Search goes through all blocks in the range of the query, and returns
+// a TxProof for every matching tx.
+//
+// Every block must match the DataHash of the commit at its height, so
+// the node cannot hide any txs from us, and every proof is validated
+// against that commit.
+func (s TxSearcher) Search(q TxQuery) ([]TxProof, error) {
+	if len(q.Tags) > 0 && s.taggers.Len() == 0 {
+		return nil, errors.New("Cannot search for tags without a registered tagger")
+	}
+
+	min, max := q.MinHeight, q.MaxHeight
+	if min < 1 {
+		min = 1
+	}
+	if max == 0 {
+		status, err := s.node.Status()
+		if err != nil {
+			return nil, err
+		}
+		max = status.LatestBlockHeight
+	}
+	if max < min {
+		return nil, errors.Errorf("Empty height range %d-%d", min, max)
+	}
+	if max-min >= searchLimit {
+		return nil, errors.Errorf("Can only search %d blocks at once", searchLimit)
+	}
+
+	res := []TxProof{}
+	for h := min; h <= max; h++ {
+		block, err := s.node.Block(h)
+		if err != nil {
+			return nil, err
+		}
+		commit, err := s.node.Commit(h)
+		if err != nil {
+			return nil, err
+		}
+		check := lc.CheckpointFromResult(commit)
+		err = ValidateBlock(block.Block, check)
+		if err != nil {
+			return nil, err
+		}
+
+		txs := block.Block.Data.Txs
+		for i, tx := range txs {
+			ok, err := s.match(q, tx)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			proof := NewTxProof(uint64(h), txs.Proof(i))
+			err = proof.Validate(check)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, proof)
+		}
+	}
+	return res, nil
+}
+
+func (s TxSearcher) match(q TxQuery, tx []byte) (bool, error) {
+	if len(q.Tags) == 0 {
+		return true, nil
+	}
+	tags := s.taggers.Tags(tx)
+	if tags == nil {
+		return false, errors.Errorf("No tagger understands tx %X", types.Tx(tx).Hash())
+	}
+	return q.Match(tags), nil
+}
@@ -0,0 +1,149 @@
+package proofs_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/light-client/proofs"
+	merktest "github.com/tendermint/merkleeyes/testutil"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestParseTxQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		query    string
+		min, max int
+		tags     map[string]string
+		valid    bool
+	}{
+		{"", 0, 0, map[string]string{}, true},
+		{"height=5", 5, 5, map[string]string{}, true},
+		{"height>5 AND height<=20", 6, 20, map[string]string{}, true},
+		{"height>=5 AND height<20 AND sender='ABCD'", 5, 19,
+			map[string]string{"sender": "ABCD"}, true},
+		{"height>0", 1, 0, map[string]string{}, true},
+		// all conditions must hold
+		{"height>=10 AND height>=5", 10, 0, map[string]string{}, true},
+		{"height=5 AND height<10", 5, 5, map[string]string{}, true},
+		{"height<=20 AND height<30 AND height>2", 3, 20, map[string]string{}, true},
+		{"height>10 AND height<5", 0, 0, nil, false},
+		{"sender>ABCD", 0, 0, nil, false},
+		// heights start at 1
+		{"height=0", 0, 0, nil, false},
+		{"height<1", 0, 0, nil, false},
+		{"height>=-5", 0, 0, nil, false},
+		{"height=foo", 0, 0, nil, false},
+		{"no-operator", 0, 0, nil, false},
+	}
+
+	for i, tc := range cases {
+		q, err := proofs.ParseTxQuery(tc.query)
+		if !tc.valid {
+			assert.NotNil(err, "%d", i)
+			continue
+		}
+		if assert.Nil(err, "%d: %+v", i, err) {
+			assert.Equal(tc.min, q.MinHeight, "%d", i)
+			assert.Equal(tc.max, q.MaxHeight, "%d", i)
+			assert.Equal(tc.tags, q.Tags, "%d", i)
+		}
+	}
+}
+
+// fixedTagger only understands txs starting with prefix
+type fixedTagger struct {
+	prefix string
+	tags   map[string]string
+}
+
+func (f fixedTagger) Tags(tx []byte) (map[string]string, error) {
+	if !strings.HasPrefix(string(tx), f.prefix) {
+		return nil, errors.New("unknown tx")
+	}
+	return f.tags, nil
+}
+
+func TestTaggers(t *testing.T) {
+	assert := assert.New(t)
+
+	taggers := proofs.NewTaggers()
+	assert.Nil(taggers.Tags([]byte("foo")))
+
+	taggers.Register("a", fixedTagger{"f", map[string]string{"app": "a", "x": "1"}})
+	taggers.Register("b", fixedTagger{"", map[string]string{"app": "b", "y": "2"}})
+	assert.Equal(2, taggers.Len())
+
+	// the first registered tagger wins
+	assert.Equal(map[string]string{"app": "a", "x": "1", "y": "2"}, taggers.Tags([]byte("foo")))
+	assert.Equal(map[string]string{"app": "b", "y": "2"}, taggers.Tags([]byte("bar")))
+
+	// replacing keeps the order
+	taggers.Register("a", fixedTagger{"b", map[string]string{"app": "c"}})
+	assert.Equal(2, taggers.Len())
+	assert.Equal(map[string]string{"app": "c", "y": "2"}, taggers.Tags([]byte("bar")))
+}
+
+func TestTxSearch(t *testing.T) {
+	assert, require := assert.New(t), require.New(t)
+
+	cl := getLocalClient()
+	taggers := proofs.NewTaggers()
+	searcher := proofs.NewTxSearcher(cl, taggers)
+
+	// post a few txs
+	txs := []types.Tx{}
+	heights := []int{}
+	for i := 0; i < 3; i++ {
+		_, _, btx := merktest.MakeTxKV()
+		br, err := cl.BroadcastTxCommit(types.Tx(btx))
+		require.Nil(err, "%+v", err)
+		require.EqualValues(0, br.DeliverTx.Code)
+		txs = append(txs, btx)
+		heights = append(heights, br.Height)
+	}
+
+	// everything in the range
+	q := proofs.TxQuery{MinHeight: heights[0], MaxHeight: heights[2]}
+	found, err := searcher.Search(q)
+	require.Nil(err, "%+v", err)
+	assert.True(len(found) >= 3, "%d", len(found))
+	for _, pr := range found {
+		check := getCheckForHeight(t, cl, int(pr.Height))
+		assert.Nil(pr.Validate(check))
+	}
+
+	// we cannot search for tags without a tagger
+	q.Tags = map[string]string{"hash": fmt.Sprintf("%X", txs[1].Hash())}
+	_, err = searcher.Search(q)
+	assert.NotNil(err)
+
+	// nor if no tagger understands the txs
+	taggers.Register("none", fixedTagger{"no-such-prefix", nil})
+	_, err = searcher.Search(q)
+	assert.NotNil(err)
+
+	// just one tx by tag
+	taggers.Register("hash", proofs.HashTagger{})
+	found, err = searcher.Search(q)
+	require.Nil(err, "%+v", err)
+	if assert.Equal(1, len(found)) {
+		assert.EqualValues(txs[1], found[0].Data())
+		assert.EqualValues(heights[1], found[0].Height)
+	}
+
+	// nothing outside of the range
+	q.MinHeight = heights[2]
+	found, err = searcher.Search(q)
+	require.Nil(err, "%+v", err)
+	assert.Equal(0, len(found))
+
+	// we don't scan the whole chain
+	_, err = searcher.Search(proofs.TxQuery{MinHeight: 1, MaxHeight: 5000})
+	assert.NotNil(err)
+}
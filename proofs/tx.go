@@ -3,20 +3,24 @@ package proofs
 import (
 	"github.com/pkg/errors"
 	wire "github.com/tendermint/go-wire"
+	"github.com/tendermint/go-wire/data"
 	lc "github.com/tendermint/light-client"
 	"github.com/tendermint/tendermint/rpc/client"
 	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tmlibs/merkle"
 )
 
 var _ lc.Prover = TxProver{}
 var _ lc.Proof = TxProof{}
 
-// we store up to 10MB as a proof, as we need an entire block! right now
-const txLimit = 10 * 1000 * 1000
+// we limit proofs to 1MB to stop overflow attacks, this is plenty for
+// one tx and its merkle branch
+const txLimit = 1000 * 1000
 
-// TxProver provides positive proofs of key-value pairs in the abciapp.
-//
-// TODO: also support negative proofs (this key is not set)
+// a block would need more than 2^64 txs for a longer branch
+const maxAunts = 64
+
+// TxProver provides proofs that a tx was included in a block.
 type TxProver struct {
 	node client.Client
 }
@@ -25,39 +29,62 @@ func NewTxProver(node client.Client) TxProver {
 	return TxProver{node: node}
 }
 
-// Get tries to download a merkle hash for app state on this key from
+// Get tries to download a merkle proof for the tx with this hash from
 // the tendermint node.
 //
 // Important: key must be Tx.Hash()
-// Height is completely ignored for now :(
+// If h is not 0, the tx must be in the block at height h, or we return
+// an ErrHeightMismatch.
 func (t TxProver) Get(key []byte, h uint64) (lc.Proof, error) {
 	res, err := t.node.Tx(key, true)
 	if err != nil {
 		return nil, err
 	}
-
-	// and build a proof for lighter storage
-	proof := TxProof{
-		Height: uint64(res.Height),
-		Proof:  res.Proof,
+	if h != 0 && uint64(res.Height) != h {
+		return nil, lc.ErrHeightMismatch(int(h), res.Height)
 	}
-	return proof, err
+	return NewTxProof(uint64(res.Height), res.Proof), nil
 }
 
 func (t TxProver) Unmarshal(data []byte) (pr lc.Proof, err error) {
+	if len(data) > txLimit {
+		return nil, errors.Errorf("Proof of %d bytes is too large", len(data))
+	}
 	var proof TxProof
 	err = errors.WithStack(wire.ReadBinaryBytes(data, &proof))
 	return proof, err
 }
 
-// TxProof checks ALL txs for one block... we need a better way!
+// TxProof is a compact proof that one tx is in the block at Height.
+//
+// It only contains the tx, its position, and the merkle branch to the
+// DataHash of the header, so it is small enough for mobile clients.
 type TxProof struct {
-	Height uint64
-	Proof  types.TxProof
+	Height uint64       `json:"height"`
+	Index  int          `json:"index"`
+	Total  int          `json:"total"`
+	Tx     data.Bytes   `json:"tx"`
+	Aunts  []data.Bytes `json:"aunts"`
+}
+
+// NewTxProof strips the tendermint proof down to what we need to validate
+// it against a header
+func NewTxProof(height uint64, proof types.TxProof) TxProof {
+	aunts := make([]data.Bytes, len(proof.Proof.Aunts))
+	for i, a := range proof.Proof.Aunts {
+		aunts[i] = a
+	}
+	return TxProof{
+		Height: height,
+		Index:  proof.Index,
+		Total:  proof.Total,
+		Tx:     data.Bytes(proof.Data),
+		Aunts:  aunts,
+	}
 }
 
 func (p TxProof) Data() []byte {
-	return p.Proof.Data
+	return p.Tx
 }
 
 func (p TxProof) BlockHeight() uint64 {
@@ -68,7 +95,23 @@ func (p TxProof) Validate(check lc.Checkpoint) error {
 	if uint64(check.Height()) != p.Height {
 		return lc.ErrHeightMismatch(int(p.Height), check.Height())
 	}
-	return p.Proof.Validate(check.Header.DataHash)
+	if p.Index < 0 || p.Index >= p.Total {
+		return errors.Errorf("Invalid index %d of %d txs", p.Index, p.Total)
+	}
+	if len(p.Tx) > txLimit || len(p.Aunts) > maxAunts {
+		return errors.New("Proof too large")
+	}
+
+	aunts := make([][]byte, len(p.Aunts))
+	for i, a := range p.Aunts {
+		aunts[i] = a
+	}
+	proof := merkle.SimpleProof{Aunts: aunts}
+	leaf := types.Tx(p.Tx).Hash()
+	if !proof.Verify(p.Index, p.Total, leaf, check.Header.DataHash) {
+		return errors.New("Tx proof doesn't match DataHash")
+	}
+	return nil
 }
 
 func (p TxProof) Marshal() ([]byte, error) {
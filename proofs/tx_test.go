@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tendermint/go-wire/data"
+	lc "github.com/tendermint/light-client"
 	"github.com/tendermint/light-client/proofs"
 	merktest "github.com/tendermint/merkleeyes/testutil"
 	"github.com/tendermint/tendermint/types"
@@ -43,6 +45,10 @@ func TestTxProofs(t *testing.T) {
 	require.NotNil(err)
 	_, err = prover.Get(tx, uint64(h+1))
 	require.NotNil(err)
+	// the tx is not in another block
+	_, err = prover.Get(tx.Hash(), uint64(h+1))
+	require.NotNil(err)
+	assert.True(lc.IsHeightMismatchErr(err), "%+v", err)
 
 	// matches and validates with post-tx header
 	check := getCheckForHeight(t, cl, h)
@@ -57,8 +63,25 @@ func TestTxProofs(t *testing.T) {
 	txpr, ok := pr.(proofs.TxProof)
 	if assert.True(ok) {
 		assert.EqualValues(tx, txpr.Data())
+
+		// it survives a trip through json
+		js, err := data.ToJSON(txpr)
+		require.Nil(err, "%+v", err)
+		var loaded proofs.TxProof
+		err = data.FromJSON(js, &loaded)
+		require.Nil(err, "%+v", err)
+		assert.Nil(loaded.Validate(check))
+
+		// and we only need a branch, not the whole block
+		bz, err := txpr.Marshal()
+		require.Nil(err, "%+v", err)
+		assert.True(len(bz) < 1000, "%d", len(bz))
 	}
 
+	// huge proofs are rejected before we parse them
+	_, err = prover.Unmarshal(make([]byte, 2000*1000))
+	assert.NotNil(err)
+
 	// make sure we read/write properly, and any changes to the serialized
 	// object are invalid proof (2000 random attempts)
 	testSerialization(t, prover, pr, check, 2000)